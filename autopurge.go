@@ -0,0 +1,81 @@
+package arigo
+
+import (
+	"sync"
+	"time"
+)
+
+// autoPurge removes the download results of stopped downloads once they reach a maximum age.
+type autoPurge struct {
+	maxAge time.Duration
+	remove func(gid string)
+
+	mut     sync.Mutex
+	timers  map[string]*time.Timer
+	stopped bool
+}
+
+func newAutoPurge(maxAge time.Duration, remove func(gid string)) *autoPurge {
+	return &autoPurge{
+		maxAge: maxAge,
+		remove: remove,
+		timers: make(map[string]*time.Timer),
+	}
+}
+
+// track schedules the removal of the download result of the download with the given gid.
+// Tracking a gid again restarts its countdown.
+func (p *autoPurge) track(gid string) {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	if p.stopped {
+		return
+	}
+
+	if timer, ok := p.timers[gid]; ok {
+		timer.Stop()
+	}
+
+	var timer *time.Timer
+	timer = time.AfterFunc(p.maxAge, func() {
+		p.mut.Lock()
+		if p.timers[gid] == timer {
+			delete(p.timers, gid)
+		}
+		p.mut.Unlock()
+
+		p.remove(gid)
+	})
+
+	p.timers[gid] = timer
+}
+
+// stop cancels all scheduled removals.
+func (p *autoPurge) stop() {
+	p.mut.Lock()
+	defer p.mut.Unlock()
+
+	p.stopped = true
+	for gid, timer := range p.timers {
+		timer.Stop()
+		delete(p.timers, gid)
+	}
+}
+
+// startAutoPurge starts tracking when downloads stop to remove their results
+// once they're older than the maximum age set using WithAutoPurge().
+func (c *Client) startAutoPurge() {
+	c.autoPurge = newAutoPurge(c.autoPurgeAge, func(gid string) {
+		// the result may have been removed by someone else already.
+		_ = c.RemoveDownloadResult(gid)
+	})
+
+	track := func(event *DownloadEvent) {
+		c.autoPurge.track(event.GID)
+	}
+
+	for _, evtType := range []EventType{StopEvent, CompleteEvent, ErrorEvent} {
+		c.Subscribe(evtType, track)
+	}
+}
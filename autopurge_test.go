@@ -0,0 +1,38 @@
+package arigo
+
+import (
+	"github.com/jae-jae/arigo/pkg/aria2proto"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestWithAutoPurge(t *testing.T) {
+	removed := make(chan interface{}, 2)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		if method == aria2proto.RemoveDownloadResult {
+			removed <- params[0]
+		}
+
+		return "OK"
+	}, WithAutoPurge(50*time.Millisecond))
+
+	client.handleNotification(aria2proto.OnDownloadComplete, []byte(`[{"gid": "1"}]`))
+	client.handleNotification(aria2proto.OnDownloadPause, []byte(`[{"gid": "2"}]`))
+
+	select {
+	case gid := <-removed:
+		assert.Equal(t, "1", gid)
+	case <-time.After(time.Second):
+		t.Fatal("download result wasn't removed")
+	}
+
+	client.handleNotification(aria2proto.OnDownloadError, []byte(`[{"gid": "3"}]`))
+	assert.NoError(t, client.Close())
+
+	select {
+	case gid := <-removed:
+		t.Fatalf("download result %v removed after the client was closed", gid)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
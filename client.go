@@ -1,17 +1,26 @@
 package arigo
 
 import (
+	"bytes"
 	"context"
+	"crypto/rand"
+	"crypto/tls"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"github.com/cenkalti/rpc2"
-	"github.com/cenkalti/rpc2/jsonrpc"
+	"fmt"
 	"github.com/gorilla/websocket"
 	"github.com/jae-jae/arigo/internal/pkg/wsrpc"
 	"github.com/jae-jae/arigo/pkg/aria2proto"
+	"io/ioutil"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -24,8 +33,71 @@ var (
 	ErrDownloadError = errors.New("download encountered error")
 	// ErrDownloadStopped is the error returned when a download is stopped
 	ErrDownloadStopped = errors.New("download stopped")
+	// ErrConnectionLost is returned by calls which were in-flight when the connection to aria2 was lost
+	// and by calls made while the client is reconnecting.
+	ErrConnectionLost = wsrpc.ErrConnectionLost
+	// ErrClientClosed is returned for calls made after the client was closed
+	// and for calls which were still in-flight when the connection was closed.
+	ErrClientClosed = wsrpc.ErrClosed
+	// ErrMessageTooLarge is returned by calls which were in-flight when aria2 sent a message
+	// exceeding the size set using WithMaxMessageSize().
+	// The connection is closed in that case, just like when it's lost.
+	ErrMessageTooLarge = wsrpc.ErrMessageTooLarge
+	// ErrInvalidPositionBehaviour is returned when an unknown PositionSetBehaviour is passed
+	ErrInvalidPositionBehaviour = errors.New("invalid position behaviour")
+	// ErrInvalidGID is returned when a new download is added using a GID option which aria2 would reject.
+	// aria2 requires a hex string of 16 characters and reserves the GID consisting of zeros only.
+	ErrInvalidGID = errors.New("gid must be a hex string of 16 characters which isn't all zeros")
+	// ErrDuplicateDownload is returned when aria2 rejects a new download because it is already registered.
+	// A DownloadError whose code is SameFileBeingDownloaded or SameInfoHashBeingDownloaded matches it as well.
+	ErrDuplicateDownload = errors.New("download is already registered")
+	// ErrMetadataOnly is returned when the metadata of a magnet link was downloaded
+	// but aria2 didn't add a download for the torrent,
+	// for example because the BTMetadataOnly or FollowTorrent options are used.
+	ErrMetadataOnly = errors.New("metadata download didn't create a torrent download")
 )
 
+// RPCError represents an error object returned by aria2 in response to a call.
+// All client methods return it when aria2 rejects a call,
+// use errors.As to inspect the code.
+type RPCError = wsrpc.Error
+
+// DownloadError represents the error aria2 reported for a download.
+type DownloadError struct {
+	Code    ExitStatus // The code of the error
+	Message string     // The human readable error message
+}
+
+func (e *DownloadError) Error() string {
+	if e.Message == "" {
+		return ErrDownloadError.Error() + ": " + e.Code.Description()
+	}
+
+	return ErrDownloadError.Error() + ": " + e.Message
+}
+
+// Is reports whether target is ErrDownloadError.
+// This allows checking for download errors using errors.Is.
+// If aria2 was already downloading the same file or torrent, target may also be ErrDuplicateDownload.
+func (e *DownloadError) Is(target error) bool {
+	if target == ErrDuplicateDownload {
+		return e.Code == SameFileBeingDownloaded || e.Code == SameInfoHashBeingDownloaded
+	}
+
+	return target == ErrDownloadError
+}
+
+// duplicateDownloadError returns ErrDuplicateDownload if err is the error aria2 returns
+// when a download is added using a GID which is already registered.
+// Other errors are returned unchanged.
+func duplicateDownloadError(err error) error {
+	if rpcErr, ok := err.(*RPCError); ok && strings.HasSuffix(rpcErr.Message, " is not unique.") {
+		return ErrDuplicateDownload
+	}
+
+	return err
+}
+
 // URIs creates a string slice from the given uris.
 // This is a convenience function for the various client
 // methods that accept a slice of URIs (strings).
@@ -34,93 +106,487 @@ func URIs(uris ...string) []string {
 }
 
 // Client represents a connection to an aria2 rpc interface over websocket.
+// It is safe to use from multiple goroutines.
 type Client struct {
-	rpcClient *rpc2.Client
-	closed    bool
+	mut     sync.RWMutex
+	conn    RPCConn
+	closed  bool
+	closing chan struct{}
+	// calls tracks the calls which are in-flight.
+	calls sync.WaitGroup
+
+	// dial establishes a new WebSocket connection. It is nil if the client
+	// wasn't created by the Dial function.
+	dial func() (*websocket.Conn, error)
 
 	authToken string
 
 	evtTarget eventTarget
+
+	pollInterval   time.Duration
+	requestTimeout time.Duration
+	tlsConfig      *tls.Config
+	header         http.Header
+	netDial        func(ctx context.Context, network, addr string) (net.Conn, error)
+	maxMessageSize int64
+
+	keepAliveInterval time.Duration
+	keepAliveTimeout  time.Duration
+
+	reconnectPolicy    *ReconnectPolicy
+	reconnectListeners []ReconnectListener
+	resumeTarget       resumeTarget
+	resumeReconcile    bool
+	resumeStopped      uint
+
+	retryPolicy *RetryPolicy
+
+	errBufferSize int
+	errs          chan error
+
+	stateMut     sync.Mutex
+	state        ConnState
+	stateChanges chan ConnState
+
+	logger      FrameLogger
+	logSecret   bool
+	idGenerator func() string
+
+	metrics Metrics
+
+	autoPurgeAge time.Duration
+	autoPurge    *autoPurge
+
+	countsInterval time.Duration
+	counts         *countTracker
+	countsResumed  chan struct{}
 }
 
-// NewClient creates a new client.
+// NewClient creates a new client from an established WebSocket connection.
 // The client needs to be manually ran
 // using the Run method.
-func NewClient(rpcClient *rpc2.Client, authToken string) Client {
-	client := Client{
-		rpcClient: rpcClient,
-		authToken: authToken,
-		closed:    false,
+func NewClient(ws *websocket.Conn, authToken string, options ...ClientOption) *Client {
+	client := newClient(authToken, options)
+	client.conn = client.newConn(ws)
+	client.setState(StateConnected)
+
+	return client
+}
+
+// NewClientWithConn creates a new client which uses the given RPCConn.
+// This makes it possible to use the client with a custom transport
+// or a fake one for testing.
+// The client needs to be manually ran using the Run method.
+// Options which only concern WebSocket connections, such as WithReconnect(), have no effect.
+func NewClientWithConn(conn RPCConn, authToken string, options ...ClientOption) *Client {
+	client := newClient(authToken, options)
+	client.conn = conn
+	client.setState(StateConnected)
+
+	return client
+}
+
+// newClient creates a new client without a connection.
+func newClient(authToken string, options []ClientOption) *Client {
+	client := &Client{
+		authToken:     authToken,
+		closed:        false,
+		closing:       make(chan struct{}),
+		pollInterval:  DefaultPollInterval,
+		errBufferSize: DefaultErrorBufferSize,
+		header:        http.Header{},
+		metrics:       noopMetrics{},
+	}
+
+	for _, option := range options {
+		option(client)
 	}
 
-	rpcClient.Handle(aria2proto.OnDownloadStart, client.onDownloadStart)
-	rpcClient.Handle(aria2proto.OnDownloadPause, client.onDownloadPause)
-	rpcClient.Handle(aria2proto.OnDownloadStop, client.onDownloadStop)
-	rpcClient.Handle(aria2proto.OnDownloadComplete, client.onDownloadComplete)
-	rpcClient.Handle(aria2proto.OnDownloadError, client.onDownloadError)
-	rpcClient.Handle(aria2proto.OnBTDownloadComplete, client.onBTDownloadComplete)
+	client.errs = make(chan error, client.errBufferSize)
+	client.stateChanges = make(chan ConnState, stateBufferSize)
+
+	if client.autoPurgeAge > 0 {
+		client.startAutoPurge()
+	}
+
+	if client.countsInterval > 0 {
+		client.startCounts()
+	}
 
 	return client
 }
 
 // Dial creates a new connection to an aria2 rpc interface.
 // It returns a new client.
-func Dial(url string, authToken string) (client Client, err error) {
-	dialer := websocket.Dialer{}
+//
+// Secure WebSocket connections are established for urls using the "wss" scheme.
+// Use the WithTLSConfig() option to configure them.
+// Additional headers for the handshake can be set using WithHeader() and WithOrigin().
+// Use WithNetDialer() to connect through a proxy.
+func Dial(url string, authToken string, options ...ClientOption) (*Client, error) {
+	client := newClient(authToken, options)
+
+	dialer := websocket.Dialer{TLSClientConfig: client.tlsConfig, NetDialContext: client.netDial}
+	dial := func() (*websocket.Conn, error) {
+		ws, _, err := dialer.Dial(url, client.header)
+		return ws, err
+	}
 
-	ws, _, err := dialer.Dial(url, http.Header{})
+	ws, err := dial()
 	if err != nil {
-		return
+		return nil, err
 	}
 
-	rwc := wsrpc.NewReadWriteCloser(ws)
-	codec := jsonrpc.NewJSONCodec(&rwc)
-	rpcClient := rpc2.NewClientWithCodec(codec)
-
-	client = NewClient(rpcClient, authToken)
+	client.conn = client.newConn(ws)
+	client.dial = dial
+	client.setState(StateConnected)
 	go client.Run()
 
-	return
+	return client, nil
+}
+
+// newConn creates the RPCConn for the given WebSocket connection.
+func (c *Client) newConn(ws *websocket.Conn) RPCConn {
+	if c.maxMessageSize > 0 {
+		ws.SetReadLimit(c.maxMessageSize)
+	}
+
+	conn := wsrpc.NewClient(ws)
+	conn.ErrorHandler = c.reportError
+
+	if c.logger != nil {
+		conn.FrameHandler = c.logFrame
+	}
+
+	conn.IDGenerator = c.idGenerator
+
+	if c.keepAliveInterval > 0 {
+		conn.KeepAlive(c.keepAliveInterval, c.keepAliveTimeout)
+	}
+
+	return wsConn{conn}
 }
 
-// Run runs the underlying rpcClient.
+func (c *Client) getConn() RPCConn {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	return c.conn
+}
+
+func (c *Client) isClosed() bool {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	return c.closed
+}
+
+// Run runs the read loop of the underlying connection.
 // There's no need to call this if the client
 // was created using the Dial function.
+//
+// If automatic reconnection is enabled, Run keeps running
+// until the connection can no longer be re-established.
 func (c *Client) Run() {
-	c.rpcClient.Run()
+	if c.counts != nil {
+		go c.reconcileCounts()
+	}
+
+	for {
+		err := c.getConn().Run(c.handleNotification)
+		if c.isClosed() {
+			return
+		}
+
+		c.reportError(err)
+
+		if c.dial == nil || c.reconnectPolicy == nil {
+			c.setState(StateClosed)
+			return
+		}
+
+		c.setState(StateReconnecting)
+		if !c.reconnect() {
+			c.setState(StateClosed)
+			return
+		}
+
+		c.setState(StateConnected)
+		c.metrics.IncReconnects()
+		go c.resume()
+	}
+}
+
+// resume notifies the subscriptions that the connection was re-established.
+func (c *Client) resume() {
+	event := &ResumeEvent{}
+
+	if c.resumeReconcile {
+		event.Active, event.Err = c.TellActive()
+		if event.Err == nil && c.resumeStopped > 0 {
+			event.Stopped, event.Err = c.TellStopped(-1, c.resumeStopped)
+		}
+	}
+
+	c.resumeTarget.dispatch(event)
+}
+
+// reconnect tries to re-establish the connection according to the reconnect policy.
+// It returns whether the connection was re-established.
+func (c *Client) reconnect() bool {
+	policy := *c.reconnectPolicy
+	delay := policy.InitialDelay
+
+	for attempt := uint(1); policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		timer := time.NewTimer(delay)
+		select {
+		case <-c.closing:
+			timer.Stop()
+			return false
+		case <-timer.C:
+		}
+
+		ws, err := c.dial()
+		if err == nil {
+			c.mut.Lock()
+			if c.closed {
+				c.mut.Unlock()
+				_ = ws.Close()
+				return false
+			}
+
+			c.conn = c.newConn(ws)
+			c.mut.Unlock()
+		}
+
+		for _, listener := range c.reconnectListeners {
+			listener(ReconnectEvent{Attempt: attempt, Err: err})
+		}
+
+		if err == nil {
+			return true
+		}
+
+		delay = policy.nextDelay(delay)
+	}
+
+	return false
 }
 
-// Close closes the connection to the aria2 rpc interface.
-// The client becomes unusable after that point.
+// Close closes the connection to the aria2 rpc interface right away.
+// Calls which are still in-flight return ErrClientClosed.
+// Use GracefulClose() to wait for them to complete instead.
+//
+// The client becomes unusable after that point and all of its subscriptions are closed.
 func (c *Client) Close() error {
-	c.closed = true
+	c.markClosed()
 
-	return c.rpcClient.Close()
+	return c.getConn().Close()
 }
 
-func (c *Client) onDownloadStart(_ *rpc2.Client, event *DownloadEvent, _ *interface{}) error {
-	c.evtTarget.Dispatch(StartEvent, event)
-	return nil
+// GracefulClose gracefully closes the connection to the aria2 rpc interface.
+// New calls are rejected with ErrClientClosed right away but GracefulClose waits for
+// calls which are in-flight to complete before closing the connection.
+// If ctx expires first, the connection is closed anyway, the remaining calls
+// return ErrClientClosed and the error of the context is returned.
+//
+// The client becomes unusable after that point and all of its subscriptions are closed.
+func (c *Client) GracefulClose(ctx context.Context) error {
+	c.markClosed()
+
+	drained := make(chan struct{})
+	go func() {
+		c.calls.Wait()
+		close(drained)
+	}()
+
+	var err error
+	select {
+	case <-drained:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	if closeErr := c.getConn().Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
 }
-func (c *Client) onDownloadPause(_ *rpc2.Client, event *DownloadEvent, _ *interface{}) error {
-	c.evtTarget.Dispatch(PauseEvent, event)
-	return nil
+
+// markClosed marks the client as closed, in which case no new calls are accepted.
+func (c *Client) markClosed() {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.closing)
+	}
+
+	if c.autoPurge != nil {
+		c.autoPurge.stop()
+	}
+
+	c.setState(StateClosed)
 }
-func (c *Client) onDownloadStop(_ *rpc2.Client, event *DownloadEvent, _ *interface{}) error {
-	c.evtTarget.Dispatch(StopEvent, event)
-	return nil
+
+// call calls the given aria2 method on the current connection.
+// The call is retried according to the retry policy.
+func (c *Client) call(ctx context.Context, method string, args []interface{}, reply interface{}) error {
+	for attempt := uint(1); ; attempt++ {
+		err := c.callOnce(ctx, method, args, reply)
+
+		policy := c.retryPolicy
+		if err == nil || policy == nil || attempt >= policy.MaxAttempts ||
+			!idempotentMethods[method] || !isTransientError(err) {
+			return err
+		}
+
+		timer := time.NewTimer(policy.Delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-c.closing:
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
 }
-func (c *Client) onDownloadComplete(_ *rpc2.Client, event *DownloadEvent, _ *interface{}) error {
-	c.evtTarget.Dispatch(CompleteEvent, event)
-	return nil
+
+// callOnce calls the given aria2 method on the current connection.
+func (c *Client) callOnce(ctx context.Context, method string, args []interface{}, reply interface{}) error {
+	c.mut.RLock()
+	if c.closed {
+		c.mut.RUnlock()
+		return ErrClientClosed
+	}
+
+	// calls must only be added while holding the lock, otherwise
+	// they could race with the Wait in GracefulClose.
+	c.calls.Add(1)
+	conn := c.conn
+	c.mut.RUnlock()
+
+	defer c.calls.Done()
+
+	if _, ok := ctx.Deadline(); !ok && c.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.requestTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := conn.Call(ctx, method, args, reply)
+	c.metrics.ObserveCall(method, time.Since(start), err)
+
+	return err
 }
-func (c *Client) onDownloadError(_ *rpc2.Client, event *DownloadEvent, _ *interface{}) error {
-	c.evtTarget.Dispatch(ErrorEvent, event)
-	return nil
+
+var notificationEvents = map[string]EventType{
+	aria2proto.OnDownloadStart:      StartEvent,
+	aria2proto.OnDownloadPause:      PauseEvent,
+	aria2proto.OnDownloadStop:       StopEvent,
+	aria2proto.OnDownloadComplete:   CompleteEvent,
+	aria2proto.OnDownloadError:      ErrorEvent,
+	aria2proto.OnBTDownloadComplete: BTCompleteEvent,
 }
-func (c *Client) onBTDownloadComplete(_ *rpc2.Client, event *DownloadEvent, _ *interface{}) error {
-	c.evtTarget.Dispatch(BTCompleteEvent, event)
-	return nil
+
+func (c *Client) handleNotification(method string, params json.RawMessage) {
+	c.metrics.IncNotifications(method)
+
+	evtType, ok := notificationEvents[method]
+	if !ok {
+		return
+	}
+
+	var events []DownloadEvent
+	if err := json.Unmarshal(params, &events); err != nil {
+		c.reportError(fmt.Errorf("couldn't decode %s notification: %v", method, err))
+		return
+	}
+
+	for i := range events {
+		c.evtTarget.Dispatch(evtType, &events[i])
+	}
+}
+
+// Errors returns a channel which receives the errors which occur in the background,
+// for example messages which can't be decoded or the loss of the connection.
+// Receiving from the channel is optional, it never blocks the client.
+// Errors are discarded while the buffer of the channel, set by WithErrorBuffer(), is full.
+func (c *Client) Errors() <-chan error {
+	return c.errs
+}
+
+// stateBufferSize is the capacity of the channel returned by Client.StateChanges().
+const stateBufferSize = 8
+
+// State returns the current state of the connection to aria2.
+func (c *Client) State() ConnState {
+	c.stateMut.Lock()
+	defer c.stateMut.Unlock()
+
+	return c.state
+}
+
+// StateChanges returns a channel which receives the state of the connection every time it changes.
+// Receiving from the channel is optional, it never blocks the client.
+// If the buffer of the channel is full, the oldest change is discarded,
+// so the most recent state is always delivered.
+// The channel is closed after StateClosed was delivered.
+func (c *Client) StateChanges() <-chan ConnState {
+	return c.stateChanges
+}
+
+// setState changes the state of the connection and sends it to the state changes channel.
+// Once the client reached StateClosed, the state no longer changes.
+func (c *Client) setState(state ConnState) {
+	c.stateMut.Lock()
+	defer c.stateMut.Unlock()
+
+	if c.state == state || c.state == StateClosed {
+		return
+	}
+
+	c.state = state
+
+	select {
+	case c.stateChanges <- state:
+	default:
+		// make room by discarding the oldest change
+		select {
+		case <-c.stateChanges:
+		default:
+		}
+
+		c.stateChanges <- state
+	}
+
+	if state == StateClosed {
+		close(c.stateChanges)
+	}
+}
+
+// reportError sends err to the errors channel if there's room for it.
+func (c *Client) reportError(err error) {
+	select {
+	case c.errs <- err:
+	default:
+	}
+}
+
+// logFrame passes frame to the logger.
+// Unless WithoutLogRedaction() is used, the secret token is replaced in the frame.
+func (c *Client) logFrame(dir Direction, frame []byte) {
+	if !c.logSecret && c.authToken != "" {
+		token, _ := json.Marshal("token:" + c.authToken)
+		frame = bytes.Replace(frame, token, []byte(`"token:[REDACTED]"`), -1)
+	}
+
+	c.logger(dir, frame)
 }
 
 // Subscribe registers the given listener for an event.
@@ -129,27 +595,252 @@ func (c *Client) Subscribe(evtType EventType, listener EventListener) Unsubscrib
 	return c.evtTarget.Subscribe(evtType, listener)
 }
 
+// DownloadCallback represents a function which is called with a download
+// and its status when an event occurs.
+type DownloadCallback func(gid GID, status Status)
+
+// onEvent registers callback for the given event.
+// The status is requested once the event occurs, which happens in a separate goroutine
+// so neither the connection nor other listeners are blocked by the callback.
+func (c *Client) onEvent(evtType EventType, callback DownloadCallback) UnsubscribeFunc {
+	return c.Subscribe(evtType, func(event *DownloadEvent) {
+		go func() {
+			status, err := c.TellStatus(event.GID)
+			if err != nil {
+				status = Status{GID: event.GID}
+			}
+
+			callback(c.GetGID(event.GID), status)
+		}()
+	})
+}
+
+// OnStart registers a callback which is called every time a download is started.
+// If the status of the download can't be retrieved, only the GID of the status is set.
+// Multiple callbacks can be registered, call the returned function to remove the callback.
+func (c *Client) OnStart(callback DownloadCallback) UnsubscribeFunc {
+	return c.onEvent(StartEvent, callback)
+}
+
+// OnComplete registers a callback which is called every time a download is completed.
+// See OnStart() for details.
+func (c *Client) OnComplete(callback DownloadCallback) UnsubscribeFunc {
+	return c.onEvent(CompleteEvent, callback)
+}
+
+// OnError registers a callback which is called every time a download stops because of an error.
+// See OnStart() for details.
+func (c *Client) OnError(callback DownloadCallback) UnsubscribeFunc {
+	return c.onEvent(ErrorEvent, callback)
+}
+
+// NewSubscription creates a Subscription which delivers all download events over channels.
+// bufferSize is the capacity of each channel.
+// The subscription must be closed using its Close method once it's no longer needed.
+func (c *Client) NewSubscription(bufferSize int) *Subscription {
+	return c.NewSubscriptionWithOptions(SubscriptionOptions{BufferSize: bufferSize})
+}
+
+// NewSubscriptionWithOptions is like NewSubscription() but the subscription is configured
+// using the given options.
+func (c *Client) NewSubscriptionWithOptions(options SubscriptionOptions) *Subscription {
+	return newSubscription(context.Background(), &c.evtTarget, c, "", options)
+}
+
+// NewSubscriptionWithContext is like NewSubscriptionWithOptions() but the subscription
+// is closed automatically once ctx is done.
+// This ties the subscription to the lifetime of ctx, for example the one of an HTTP request.
+//
+// If ctx is already done, its error is returned.
+// If the client is closed, ErrClientClosed is returned.
+func (c *Client) NewSubscriptionWithContext(ctx context.Context, options SubscriptionOptions) (*Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if c.isClosed() {
+		return nil, ErrClientClosed
+	}
+
+	return newSubscription(ctx, &c.evtTarget, c, "", options), nil
+}
+
 // WaitForDownload waits for a download denoted by its gid to finish.
+// If the download encountered an error, a *DownloadError is returned.
+// If the download was removed, ErrDownloadStopped is returned.
 func (c *Client) WaitForDownload(gid string) error {
-	channel := make(chan error, 1)
+	status, err := c.WaitForDownloadWithContext(context.Background(), gid)
+	if err == nil && status.Status == StatusRemoved {
+		err = ErrDownloadStopped
+	}
+
+	return err
+}
+
+func isTerminalStatus(status DownloadStatus) bool {
+	return status == StatusCompleted || status == StatusError || status == StatusRemoved
+}
+
+// WaitForDownloadWithContext waits for a download denoted by its gid to reach
+// a terminal state (completed, error or removed).
+// It returns the final status of the download.
+//
+// The download events sent by aria2 are used to detect the state change.
+// In case an event is missed, the status is also polled in the interval set by the WithPollInterval() option.
+//
+// If the download encountered an error, the status is returned together with a *DownloadError
+// describing the error.
+// The passed context can be used to stop waiting, in which case the error of the context is returned.
+func (c *Client) WaitForDownloadWithContext(ctx context.Context, gid string) (Status, error) {
+	return c.waitForDownload(ctx, gid, nil)
+}
+
+// WaitForDownloadFunc is like WaitForDownloadWithContext() but calls onUpdate with every
+// status of the download it retrieves while waiting, including the final one.
+// This can be used to display the progress of the download.
+// See WaitForDownloadFuncWithContext() for details.
+func (c *Client) WaitForDownloadFunc(gid string, onUpdate func(status Status)) (Status, error) {
+	return c.WaitForDownloadFuncWithContext(context.Background(), gid, onUpdate)
+}
+
+// WaitForDownloadFuncWithContext is like WaitForDownloadWithContext() but calls onUpdate with every
+// status of the download it retrieves while waiting, including the final one.
+//
+// The status is retrieved for every event of the download and in the interval set by the
+// WithPollInterval() option. Use a short interval to display a smooth progress.
+func (c *Client) WaitForDownloadFuncWithContext(ctx context.Context, gid string, onUpdate func(status Status)) (Status, error) {
+	return c.waitForDownload(ctx, gid, onUpdate)
+}
+
+// waitForDownload waits for a download to reach a terminal state.
+// If onUpdate isn't nil, it is called with every retrieved status and
+// the status is also retrieved for the events which don't change the state to a terminal one.
+func (c *Client) waitForDownload(ctx context.Context, gid string, onUpdate func(status Status)) (Status, error) {
+	events := make(chan struct{}, 1)
+	notify := func(event *DownloadEvent) {
+		if event.GID != gid {
+			return
+		}
+
+		select {
+		case events <- struct{}{}:
+		default:
+		}
+	}
+
+	unsubscribers := []UnsubscribeFunc{
+		c.Subscribe(CompleteEvent, notify),
+		c.Subscribe(ErrorEvent, notify),
+		c.Subscribe(StopEvent, notify),
+	}
 
-	sendResponse := func(err error) EventListener {
-		return func(*DownloadEvent) {
-			channel <- err
+	if onUpdate != nil {
+		unsubscribers = append(unsubscribers,
+			c.Subscribe(StartEvent, notify),
+			c.Subscribe(PauseEvent, notify),
+			c.Subscribe(BTCompleteEvent, notify),
+		)
+	}
+
+	defer func() {
+		for _, unsub := range unsubscribers {
+			unsub()
 		}
+	}()
+
+	var poll <-chan time.Time
+	if c.pollInterval > 0 {
+		ticker := time.NewTicker(c.pollInterval)
+		defer ticker.Stop()
+
+		poll = ticker.C
 	}
 
-	stopUnsub := c.Subscribe(StopEvent, sendResponse(ErrDownloadStopped))
-	completeUnsub := c.Subscribe(CompleteEvent, sendResponse(nil))
-	errUnsub := c.Subscribe(ErrorEvent, sendResponse(ErrDownloadError))
+	for {
+		// the status is checked right away because the download might have already
+		// finished before the event listeners were registered.
+		status, err := c.TellStatusWithContext(ctx, gid)
+		if err != nil {
+			return status, err
+		}
 
-	err := <-channel
+		if onUpdate != nil {
+			onUpdate(status)
+		}
 
-	stopUnsub()
-	completeUnsub()
-	errUnsub()
+		if isTerminalStatus(status.Status) {
+			if status.Status == StatusError {
+				err = &DownloadError{Code: status.ErrorCode, Message: status.ErrorMessage}
+			}
 
-	return err
+			return status, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return status, ctx.Err()
+		case <-c.closing:
+			return status, ErrClientClosed
+		case <-events:
+		case <-poll:
+		}
+	}
+}
+
+// WatchStatus polls the status of the download denoted by gid in the given interval
+// and sends every status to the returned status channel.
+// See WatchStatusWithContext() for details.
+func (c *Client) WatchStatus(gid string, interval time.Duration) (<-chan Status, <-chan error) {
+	return c.WatchStatusWithContext(context.Background(), gid, interval)
+}
+
+// WatchStatusWithContext polls the status of the download denoted by gid in the given interval
+// and sends every status to the returned status channel.
+// The first status is sent right away.
+//
+// Polling stops once the download reaches a terminal state (completed, error or removed),
+// after the terminal status has been sent.
+// If a call fails or ctx is done, the error is sent to the error channel and polling stops.
+// Both channels are closed once polling stopped.
+func (c *Client) WatchStatusWithContext(ctx context.Context, gid string, interval time.Duration) (<-chan Status, <-chan error) {
+	statuses := make(chan Status)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(statuses)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			status, err := c.TellStatusWithContext(ctx, gid)
+			if err != nil {
+				errs <- err
+				return
+			}
+
+			select {
+			case statuses <- status:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+
+			if isTerminalStatus(status.Status) {
+				return
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				errs <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	return statuses, errs
 }
 
 // Download adds a new download and waits for it to complete.
@@ -162,26 +853,14 @@ func (c *Client) Download(uris []string, options *Options) (status Status, err e
 // The passed context can be used to cancel the download.
 // It returns the status of the finished download.
 func (c *Client) DownloadWithContext(ctx context.Context, uris []string, options *Options) (status Status, err error) {
-	gid, err := c.AddURI(uris, options)
+	gid, err := c.AddURIWithContext(ctx, uris, options)
 	if err != nil {
 		return
 	}
 
-	downloadDone := make(chan error, 1)
-
-	go func() {
-		downloadDone <- gid.WaitForDownload()
-	}()
-
-	select {
-	case <-downloadDone:
-		status, err = gid.TellStatus()
-		if err != nil {
-			return
-		}
-	case <-ctx.Done():
+	status, err = gid.WaitForDownloadWithContext(ctx)
+	if ctxErr := ctx.Err(); ctxErr != nil && err == ctxErr {
 		_ = gid.Delete()
-		err = ctx.Err()
 	}
 
 	return
@@ -190,12 +869,17 @@ func (c *Client) DownloadWithContext(ctx context.Context, uris []string, options
 // Delete removes the download denoted by gid and deletes all corresponding files.
 // This is not an aria2 method.
 func (c *Client) Delete(gid string) (err error) {
-	err = c.Remove(gid)
+	return c.DeleteWithContext(context.Background(), gid)
+}
+
+// DeleteWithContext is like Delete() but the passed context can be used to cancel the call.
+func (c *Client) DeleteWithContext(ctx context.Context, gid string) (err error) {
+	err = c.RemoveWithContext(ctx, gid)
 	if err != nil {
 		return
 	}
 
-	files, err := c.GetFiles(gid)
+	files, err := c.GetFilesWithContext(ctx, gid)
 	if err == nil {
 		for _, file := range files {
 			_ = os.Remove(file.Path)
@@ -210,6 +894,26 @@ func (c *Client) GetGID(gid string) GID {
 	return GID{c, gid}
 }
 
+// stringSlice returns s or an empty slice if s is nil.
+// aria2 rejects null where it expects an array.
+func stringSlice(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+
+	return s
+}
+
+// keysArgs appends keys to args unless it's empty,
+// in which case aria2 returns all keys.
+func keysArgs(args []interface{}, keys []string) []interface{} {
+	if len(keys) == 0 {
+		return args
+	}
+
+	return append(args, keys)
+}
+
 func (c *Client) getArgs(args ...interface{}) []interface{} {
 	if c.authToken == "" {
 		return args
@@ -219,6 +923,62 @@ func (c *Client) getArgs(args ...interface{}) []interface{} {
 	}
 }
 
+// getMethodArgs adds the secret token to args unless method is one
+// of the system methods, which don't accept a token.
+func (c *Client) getMethodArgs(method string, args []interface{}) []interface{} {
+	if strings.HasPrefix(method, "system.") {
+		return args
+	}
+
+	return c.getArgs(args...)
+}
+
+// positionArgs appends the options and the position of a new download to args.
+// aria2 only accepts the position after the options,
+// so empty options are sent if there's a position but no options.
+func positionArgs(args []interface{}, options *Options, position uint) []interface{} {
+	if options == nil && position != QueueEndPosition {
+		options = &Options{}
+	}
+
+	if options != nil {
+		args = append(args, options)
+	}
+
+	if position != QueueEndPosition {
+		args = append(args, position)
+	}
+
+	return args
+}
+
+// validateGIDOption returns ErrInvalidGID if the GID option is set to a value aria2 rejects.
+func validateGIDOption(options *Options) error {
+	if options == nil || options.GID == "" {
+		return nil
+	}
+
+	if !IsValidGID(options.GID) || strings.Trim(options.GID, "0") == "" {
+		return ErrInvalidGID
+	}
+
+	return nil
+}
+
+// randomGID returns a random GID which is valid according to validateGIDOption.
+func randomGID() (string, error) {
+	data := make([]byte, 8)
+	for {
+		if _, err := rand.Read(data); err != nil {
+			return "", err
+		}
+
+		if gid := hex.EncodeToString(data); gid != "0000000000000000" {
+			return gid, nil
+		}
+	}
+}
+
 // AddURIAtPosition adds a new download at a specific position in the queue.
 // uris is a slice of HTTP/FTP/SFTP/BitTorrent URIs pointing to the same resource.
 // If you mix URIs pointing to different resources,
@@ -232,20 +992,21 @@ func (c *Client) getArgs(args ...interface{}) []interface{} {
 //
 // This method returns the GID of the newly registered download.
 func (c *Client) AddURIAtPosition(uris []string, position uint, options *Options) (GID, error) {
-	args := c.getArgs(uris)
+	return c.AddURIAtPositionWithContext(context.Background(), uris, position, options)
+}
 
-	if options != nil {
-		args = append(args, options)
+// AddURIAtPositionWithContext is like AddURIAtPosition() but the passed context can be used to cancel the call.
+func (c *Client) AddURIAtPositionWithContext(ctx context.Context, uris []string, position uint, options *Options) (GID, error) {
+	if err := validateGIDOption(options); err != nil {
+		return GID{}, err
 	}
 
-	if position != QueueEndPosition {
-		args = append(args, position)
-	}
+	args := positionArgs(c.getArgs(stringSlice(uris)), options, position)
 
 	var reply string
-	err := c.rpcClient.Call(aria2proto.AddURI, args, &reply)
+	err := c.call(ctx, aria2proto.AddURI, args, &reply)
 
-	return c.GetGID(reply), err
+	return c.GetGID(reply), duplicateDownloadError(err)
 }
 
 // AddURI adds a new download.
@@ -257,9 +1018,116 @@ func (c *Client) AddURIAtPosition(uris []string, position uint, options *Options
 //
 // The new download is appended to the end of the queue.
 //
-// This method returns the GID of the newly registered download.
+// This method returns the GID of the newly registered download as reported by aria2.
+// If options.GID is set, it must be a valid GID, otherwise ErrInvalidGID is returned without calling aria2.
+// If a download with the same GID is already registered, ErrDuplicateDownload is returned.
 func (c *Client) AddURI(uris []string, options *Options) (GID, error) {
-	return c.AddURIAtPosition(uris, QueueEndPosition, options)
+	return c.AddURIWithContext(context.Background(), uris, options)
+}
+
+// AddURIWithContext is like AddURI() but the passed context can be used to cancel the call.
+func (c *Client) AddURIWithContext(ctx context.Context, uris []string, options *Options) (GID, error) {
+	return c.AddURIAtPositionWithContext(ctx, uris, QueueEndPosition, options)
+}
+
+// AddURIStatus adds a new download like AddURI() and returns its initial status.
+// The download is added and its status requested in a single MultiCall,
+// so the status is returned even if a tiny download completes or fails right away.
+// For this the GID of the download must be known in advance,
+// if options.GID isn't set a random GID is assigned to the download.
+func (c *Client) AddURIStatus(uris []string, options *Options) (Status, error) {
+	return c.AddURIStatusWithContext(context.Background(), uris, options)
+}
+
+// AddURIStatusWithContext is like AddURIStatus() but the passed context can be used to cancel the call.
+func (c *Client) AddURIStatusWithContext(ctx context.Context, uris []string, options *Options) (Status, error) {
+	var opts Options
+	if options != nil {
+		if err := validateGIDOption(options); err != nil {
+			return Status{}, err
+		}
+
+		opts = options.Clone()
+	}
+
+	if opts.GID == "" {
+		gid, err := randomGID()
+		if err != nil {
+			return Status{}, err
+		}
+
+		opts.GID = gid
+	}
+
+	results, err := c.MultiCallWithContext(ctx,
+		NewMethodCall(aria2proto.AddURI, stringSlice(uris), &opts),
+		NewMethodCall(aria2proto.TellStatus, opts.GID),
+	)
+	if err != nil {
+		return Status{}, err
+	}
+
+	var gid string
+	if err := results[0].Unmarshal(&gid); err != nil {
+		return Status{}, duplicateDownloadError(err)
+	}
+
+	var status Status
+	err = results[1].Unmarshal(&status)
+
+	return status, err
+}
+
+// AddMagnet adds a download for a BitTorrent Magnet URI.
+// aria2 first downloads the metadata of the torrent in a separate download whose GID is returned.
+// Once the metadata is downloaded, aria2 adds the download of the torrent itself,
+// see Status.FollowedBy and AddMagnetAndWaitMetadata().
+func (c *Client) AddMagnet(magnet string, options *Options) (GID, error) {
+	return c.AddMagnetWithContext(context.Background(), magnet, options)
+}
+
+// AddMagnetWithContext is like AddMagnet() but the passed context can be used to cancel the call.
+func (c *Client) AddMagnetWithContext(ctx context.Context, magnet string, options *Options) (GID, error) {
+	if !(URI{URI: magnet}).IsMagnet() {
+		return GID{}, fmt.Errorf("not a magnet uri: %q", magnet)
+	}
+
+	return c.AddURIWithContext(ctx, URIs(magnet), options)
+}
+
+// AddMagnetAndWaitMetadata adds a download for a BitTorrent Magnet URI like AddMagnet()
+// and waits for the metadata to be downloaded.
+// It returns the GID of the download of the torrent which aria2 added after downloading the metadata.
+//
+// If downloading the metadata failed, a *DownloadError is returned.
+// If the metadata download was removed, ErrDownloadStopped is returned.
+// If aria2 didn't add a download for the torrent, ErrMetadataOnly is returned.
+func (c *Client) AddMagnetAndWaitMetadata(magnet string, options *Options) (GID, error) {
+	return c.AddMagnetAndWaitMetadataWithContext(context.Background(), magnet, options)
+}
+
+// AddMagnetAndWaitMetadataWithContext is like AddMagnetAndWaitMetadata() but the passed context can be used to cancel the call.
+// The context is also used to stop waiting for the metadata.
+func (c *Client) AddMagnetAndWaitMetadataWithContext(ctx context.Context, magnet string, options *Options) (GID, error) {
+	metadata, err := c.AddMagnetWithContext(ctx, magnet, options)
+	if err != nil {
+		return GID{}, err
+	}
+
+	status, err := c.WaitForDownloadWithContext(ctx, metadata.GID)
+	if err != nil {
+		return GID{}, err
+	}
+
+	if status.Status == StatusRemoved {
+		return GID{}, ErrDownloadStopped
+	}
+
+	if len(status.FollowedBy) == 0 {
+		return GID{}, ErrMetadataOnly
+	}
+
+	return c.GetGID(status.FollowedBy[0]), nil
 }
 
 // AddTorrentAtPosition adds a BitTorrent download at a specific position in the queue.
@@ -277,21 +1145,22 @@ func (c *Client) AddURI(uris []string, options *Options) (GID, error) {
 //
 // This method returns the GID of the newly registered download.
 func (c *Client) AddTorrentAtPosition(torrent []byte, uris []string, position uint, options *Options) (GID, error) {
-	encodedTorrent := base64.StdEncoding.EncodeToString(torrent)
-	args := c.getArgs(encodedTorrent, uris)
+	return c.AddTorrentAtPositionWithContext(context.Background(), torrent, uris, position, options)
+}
 
-	if options != nil {
-		args = append(args, options)
+// AddTorrentAtPositionWithContext is like AddTorrentAtPosition() but the passed context can be used to cancel the call.
+func (c *Client) AddTorrentAtPositionWithContext(ctx context.Context, torrent []byte, uris []string, position uint, options *Options) (GID, error) {
+	if err := validateGIDOption(options); err != nil {
+		return GID{}, err
 	}
 
-	if position != QueueEndPosition {
-		args = append(args, position)
-	}
+	encodedTorrent := base64.StdEncoding.EncodeToString(torrent)
+	args := positionArgs(c.getArgs(encodedTorrent, stringSlice(uris)), options, position)
 
 	var reply string
-	err := c.rpcClient.Call(aria2proto.AddTorrent, args, &reply)
+	err := c.call(ctx, aria2proto.AddTorrent, args, &reply)
 
-	return c.GetGID(reply), err
+	return c.GetGID(reply), duplicateDownloadError(err)
 }
 
 // AddTorrent adds a BitTorrent download by uploading a “.torrent” file.
@@ -307,7 +1176,46 @@ func (c *Client) AddTorrentAtPosition(torrent []byte, uris []string, position ui
 //
 // This method returns the GID of the newly registered download.
 func (c *Client) AddTorrent(torrent []byte, uris []string, options *Options) (GID, error) {
-	return c.AddTorrentAtPosition(torrent, uris, QueueEndPosition, options)
+	return c.AddTorrentWithContext(context.Background(), torrent, uris, options)
+}
+
+// AddTorrentWithContext is like AddTorrent() but the passed context can be used to cancel the call.
+func (c *Client) AddTorrentWithContext(ctx context.Context, torrent []byte, uris []string, options *Options) (GID, error) {
+	return c.AddTorrentAtPositionWithContext(ctx, torrent, uris, QueueEndPosition, options)
+}
+
+// AddTorrentFileAtPosition adds a BitTorrent download at a specific position in the queue
+// by reading the “.torrent” file located at path.
+// See AddTorrentAtPosition() for the meaning of the other parameters.
+//
+// This method returns the GID of the newly registered download.
+func (c *Client) AddTorrentFileAtPosition(path string, uris []string, position uint, options *Options) (GID, error) {
+	return c.AddTorrentFileAtPositionWithContext(context.Background(), path, uris, position, options)
+}
+
+// AddTorrentFileAtPositionWithContext is like AddTorrentFileAtPosition() but the passed context can be used to cancel the call.
+func (c *Client) AddTorrentFileAtPositionWithContext(ctx context.Context, path string, uris []string, position uint, options *Options) (GID, error) {
+	torrent, err := ioutil.ReadFile(path)
+	if err != nil {
+		return GID{}, err
+	}
+
+	return c.AddTorrentAtPositionWithContext(ctx, torrent, uris, position, options)
+}
+
+// AddTorrentFile adds a BitTorrent download by reading the “.torrent” file located at path.
+// See AddTorrent() for the meaning of the other parameters.
+//
+// The new download is appended to the end of the queue.
+//
+// This method returns the GID of the newly registered download.
+func (c *Client) AddTorrentFile(path string, uris []string, options *Options) (GID, error) {
+	return c.AddTorrentFileWithContext(context.Background(), path, uris, options)
+}
+
+// AddTorrentFileWithContext is like AddTorrentFile() but the passed context can be used to cancel the call.
+func (c *Client) AddTorrentFileWithContext(ctx context.Context, path string, uris []string, options *Options) (GID, error) {
+	return c.AddTorrentFileAtPositionWithContext(ctx, path, uris, QueueEndPosition, options)
 }
 
 // AddMetalinkAtPosition adds a Metalink download at a specific position in the queue by uploading a “.metalink” file.
@@ -319,26 +1227,27 @@ func (c *Client) AddTorrent(torrent []byte, uris []string, options *Options) (GI
 //
 // This method returns an array of GIDs of newly registered downloads.
 func (c *Client) AddMetalinkAtPosition(metalink []byte, position uint, options *Options) ([]GID, error) {
-	encodedMetalink := base64.StdEncoding.EncodeToString(metalink)
-	args := c.getArgs(encodedMetalink)
+	return c.AddMetalinkAtPositionWithContext(context.Background(), metalink, position, options)
+}
 
-	if options != nil {
-		args = append(args, options)
+// AddMetalinkAtPositionWithContext is like AddMetalinkAtPosition() but the passed context can be used to cancel the call.
+func (c *Client) AddMetalinkAtPositionWithContext(ctx context.Context, metalink []byte, position uint, options *Options) ([]GID, error) {
+	if err := validateGIDOption(options); err != nil {
+		return nil, err
 	}
 
-	if position != QueueEndPosition {
-		args = append(args, position)
-	}
+	encodedMetalink := base64.StdEncoding.EncodeToString(metalink)
+	args := positionArgs(c.getArgs(encodedMetalink), options, position)
 
 	var reply []string
-	err := c.rpcClient.Call(aria2proto.AddMetalink, args, &reply)
+	err := c.call(ctx, aria2proto.AddMetalink, args, &reply)
 
 	gids := make([]GID, len(reply))
-	for _, rawGID := range reply {
-		gids = append(gids, c.GetGID(rawGID))
+	for i, rawGID := range reply {
+		gids[i] = c.GetGID(rawGID)
 	}
 
-	return gids, err
+	return gids, duplicateDownloadError(err)
 }
 
 // AddMetalink adds a Metalink download by uploading a “.metalink” file.
@@ -348,14 +1257,60 @@ func (c *Client) AddMetalinkAtPosition(metalink []byte, position uint, options *
 //
 // This method returns an array of GIDs of newly registered downloads.
 func (c *Client) AddMetalink(metalink []byte, options *Options) ([]GID, error) {
-	return c.AddMetalinkAtPosition(metalink, QueueEndPosition, options)
+	return c.AddMetalinkWithContext(context.Background(), metalink, options)
+}
+
+// AddMetalinkWithContext is like AddMetalink() but the passed context can be used to cancel the call.
+func (c *Client) AddMetalinkWithContext(ctx context.Context, metalink []byte, options *Options) ([]GID, error) {
+	return c.AddMetalinkAtPositionWithContext(ctx, metalink, QueueEndPosition, options)
+}
+
+// AddMetalinkFileAtPosition adds a Metalink download at a specific position in the queue
+// by reading the “.metalink” file located at path.
+//
+// The new download will be inserted at position in the waiting queue.
+// If position is nil or position is larger than the current size of the queue,
+// the new download is appended to the end of the queue.
+//
+// This method returns an array of GIDs of newly registered downloads.
+func (c *Client) AddMetalinkFileAtPosition(path string, position uint, options *Options) ([]GID, error) {
+	return c.AddMetalinkFileAtPositionWithContext(context.Background(), path, position, options)
+}
+
+// AddMetalinkFileAtPositionWithContext is like AddMetalinkFileAtPosition() but the passed context can be used to cancel the call.
+func (c *Client) AddMetalinkFileAtPositionWithContext(ctx context.Context, path string, position uint, options *Options) ([]GID, error) {
+	metalink, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.AddMetalinkAtPositionWithContext(ctx, metalink, position, options)
+}
+
+// AddMetalinkFile adds a Metalink download by reading the “.metalink” file located at path.
+//
+// The new download is appended to the end of the queue.
+//
+// This method returns an array of GIDs of newly registered downloads.
+func (c *Client) AddMetalinkFile(path string, options *Options) ([]GID, error) {
+	return c.AddMetalinkFileWithContext(context.Background(), path, options)
+}
+
+// AddMetalinkFileWithContext is like AddMetalinkFile() but the passed context can be used to cancel the call.
+func (c *Client) AddMetalinkFileWithContext(ctx context.Context, path string, options *Options) ([]GID, error) {
+	return c.AddMetalinkFileAtPositionWithContext(ctx, path, QueueEndPosition, options)
 }
 
 // Remove removes the download denoted by gid.
 // If the specified download is in progress, it is first stopped.
 // The status of the removed download becomes removed.
 func (c *Client) Remove(gid string) error {
-	return c.rpcClient.Call(aria2proto.Remove, c.getArgs(gid), nil)
+	return c.RemoveWithContext(context.Background(), gid)
+}
+
+// RemoveWithContext is like Remove() but the passed context can be used to cancel the call.
+func (c *Client) RemoveWithContext(ctx context.Context, gid string) error {
+	return c.call(ctx, aria2proto.Remove, c.getArgs(gid), nil)
 }
 
 // ForceRemove removes the download denoted by gid.
@@ -363,7 +1318,12 @@ func (c *Client) Remove(gid string) error {
 // without performing any actions which take time, such as contacting BitTorrent trackers to
 // unregister the download first.
 func (c *Client) ForceRemove(gid string) error {
-	return c.rpcClient.Call(aria2proto.ForceRemove, c.getArgs(gid), nil)
+	return c.ForceRemoveWithContext(context.Background(), gid)
+}
+
+// ForceRemoveWithContext is like ForceRemove() but the passed context can be used to cancel the call.
+func (c *Client) ForceRemoveWithContext(ctx context.Context, gid string) error {
+	return c.call(ctx, aria2proto.ForceRemove, c.getArgs(gid), nil)
 }
 
 // Pause pauses the download denoted by gid.
@@ -371,12 +1331,22 @@ func (c *Client) ForceRemove(gid string) error {
 // the download is placed in the front of the queue. While the status is paused,
 // the download is not started. To change status to waiting, use the Unpause() method.
 func (c *Client) Pause(gid string) error {
-	return c.rpcClient.Call(aria2proto.Pause, c.getArgs(gid), nil)
+	return c.PauseWithContext(context.Background(), gid)
+}
+
+// PauseWithContext is like Pause() but the passed context can be used to cancel the call.
+func (c *Client) PauseWithContext(ctx context.Context, gid string) error {
+	return c.call(ctx, aria2proto.Pause, c.getArgs(gid), nil)
 }
 
 // PauseAll is equal to calling Pause() for every active/waiting download.
 func (c *Client) PauseAll() error {
-	return c.rpcClient.Call(aria2proto.PauseAll, c.getArgs(), nil)
+	return c.PauseAllWithContext(context.Background())
+}
+
+// PauseAllWithContext is like PauseAll() but the passed context can be used to cancel the call.
+func (c *Client) PauseAllWithContext(ctx context.Context) error {
+	return c.call(ctx, aria2proto.PauseAll, c.getArgs(), nil)
 }
 
 // ForcePause pauses the download denoted by gid.
@@ -384,23 +1354,43 @@ func (c *Client) PauseAll() error {
 // without performing any actions which take time, such as contacting BitTorrent trackers to
 // unregister the download first.
 func (c *Client) ForcePause(gid string) error {
-	return c.rpcClient.Call(aria2proto.ForcePause, c.getArgs(gid), nil)
+	return c.ForcePauseWithContext(context.Background(), gid)
+}
+
+// ForcePauseWithContext is like ForcePause() but the passed context can be used to cancel the call.
+func (c *Client) ForcePauseWithContext(ctx context.Context, gid string) error {
+	return c.call(ctx, aria2proto.ForcePause, c.getArgs(gid), nil)
 }
 
 // ForcePauseAll is equal to calling ForcePause() for every active/waiting download.
 func (c *Client) ForcePauseAll() error {
-	return c.rpcClient.Call(aria2proto.ForcePauseAll, c.getArgs(), nil)
+	return c.ForcePauseAllWithContext(context.Background())
+}
+
+// ForcePauseAllWithContext is like ForcePauseAll() but the passed context can be used to cancel the call.
+func (c *Client) ForcePauseAllWithContext(ctx context.Context) error {
+	return c.call(ctx, aria2proto.ForcePauseAll, c.getArgs(), nil)
 }
 
 // Unpause changes the status of the download denoted by gid from paused to waiting,
 // making the download eligible to be restarted.
 func (c *Client) Unpause(gid string) error {
-	return c.rpcClient.Call(aria2proto.Unpause, c.getArgs(gid), nil)
+	return c.UnpauseWithContext(context.Background(), gid)
+}
+
+// UnpauseWithContext is like Unpause() but the passed context can be used to cancel the call.
+func (c *Client) UnpauseWithContext(ctx context.Context, gid string) error {
+	return c.call(ctx, aria2proto.Unpause, c.getArgs(gid), nil)
 }
 
 // UnpauseAll is equal to calling Unpause() for every paused download.
 func (c *Client) UnpauseAll() error {
-	return c.rpcClient.Call(aria2proto.UnpauseAll, c.getArgs(), nil)
+	return c.UnpauseAllWithContext(context.Background())
+}
+
+// UnpauseAllWithContext is like UnpauseAll() but the passed context can be used to cancel the call.
+func (c *Client) UnpauseAllWithContext(ctx context.Context) error {
+	return c.call(ctx, aria2proto.UnpauseAll, c.getArgs(), nil)
 }
 
 // TellStatus returns the progress of the download denoted by gid.
@@ -408,17 +1398,105 @@ func (c *Client) UnpauseAll() error {
 // If specified, the returned Status only contains the keys passed to the method.
 // This is useful when you just want specific keys and avoid unnecessary transfers.
 func (c *Client) TellStatus(gid string, keys ...string) (Status, error) {
+	return c.TellStatusWithContext(context.Background(), gid, keys...)
+}
+
+// TellStatusWithContext is like TellStatus() but the passed context can be used to cancel the call.
+func (c *Client) TellStatusWithContext(ctx context.Context, gid string, keys ...string) (Status, error) {
 	var reply Status
-	err := c.rpcClient.Call(aria2proto.TellStatus, c.getArgs(gid, keys), &reply)
+	err := c.call(ctx, aria2proto.TellStatus, keysArgs(c.getArgs(gid), keys), &reply)
 
 	return reply, err
 }
 
+// bitTorrentKeys are the keys of the Status requested by TellBitTorrentStatus().
+var bitTorrentKeys = []string{"infoHash", "numSeeders", "seeder", "bittorrent", "connections"}
+
+// TellBitTorrentStatus returns the BitTorrent specific status of the download denoted by gid.
+// Only the BitTorrent related keys are requested from aria2.
+// For downloads which aren't BitTorrent downloads, the returned values are empty.
+func (c *Client) TellBitTorrentStatus(gid string) (BitTorrentStatus, InfoHashSummary, error) {
+	return c.TellBitTorrentStatusWithContext(context.Background(), gid)
+}
+
+// TellBitTorrentStatusWithContext is like TellBitTorrentStatus() but the passed context can be used to cancel the call.
+func (c *Client) TellBitTorrentStatusWithContext(ctx context.Context, gid string) (BitTorrentStatus, InfoHashSummary, error) {
+	status, err := c.TellStatusWithContext(ctx, gid, bitTorrentKeys...)
+	if err != nil {
+		return BitTorrentStatus{}, InfoHashSummary{}, err
+	}
+
+	summary := InfoHashSummary{
+		InfoHash:    status.InfoHash,
+		NumSeeders:  status.NumSeeders,
+		Seeder:      status.Seeder,
+		Connections: status.Connections,
+	}
+
+	return status.BitTorrent, summary, nil
+}
+
+// FollowedDownloads returns the status of every download which was generated as the result
+// of the download denoted by gid, see Status.FollowedBy.
+// For example, these are the downloads described in a Metalink.
+// keys does the same as in the TellStatus() method.
+func (c *Client) FollowedDownloads(gid string, keys ...string) ([]Status, error) {
+	return c.FollowedDownloadsWithContext(context.Background(), gid, keys...)
+}
+
+// FollowedDownloadsWithContext is like FollowedDownloads() but the passed context can be used to cancel the call.
+func (c *Client) FollowedDownloadsWithContext(ctx context.Context, gid string, keys ...string) ([]Status, error) {
+	status, err := c.TellStatusWithContext(ctx, gid, "followedBy")
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(status.FollowedBy))
+	for _, followed := range status.FollowedBy {
+		followedStatus, err := c.TellStatusWithContext(ctx, followed, keys...)
+		if err != nil {
+			return statuses, err
+		}
+
+		statuses = append(statuses, followedStatus)
+	}
+
+	return statuses, nil
+}
+
+// ParentDownload returns the status of the download which the download denoted by gid
+// is a part of, see Status.BelongsTo.
+// If the download has no parent, nil is returned.
+// keys does the same as in the TellStatus() method.
+func (c *Client) ParentDownload(gid string, keys ...string) (*Status, error) {
+	return c.ParentDownloadWithContext(context.Background(), gid, keys...)
+}
+
+// ParentDownloadWithContext is like ParentDownload() but the passed context can be used to cancel the call.
+func (c *Client) ParentDownloadWithContext(ctx context.Context, gid string, keys ...string) (*Status, error) {
+	status, err := c.TellStatusWithContext(ctx, gid, "belongsTo")
+	if err != nil || status.BelongsTo == "" {
+		return nil, err
+	}
+
+	parent, err := c.TellStatusWithContext(ctx, status.BelongsTo, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &parent, nil
+}
+
 // GetURIs returns the URIs used in the download denoted by gid.
 // The response is a slice of URIs.
 func (c *Client) GetURIs(gid string) ([]URI, error) {
+	return c.GetURIsWithContext(context.Background(), gid)
+}
+
+// GetURIsWithContext is like GetURIs() but the passed context can be used to cancel the call.
+func (c *Client) GetURIsWithContext(ctx context.Context, gid string) ([]URI, error) {
 	var reply []URI
-	err := c.rpcClient.Call(aria2proto.GetURIs, c.getArgs(gid), &reply)
+	err := c.call(ctx, aria2proto.GetURIs, c.getArgs(gid), &reply)
 
 	return reply, err
 }
@@ -426,8 +1504,13 @@ func (c *Client) GetURIs(gid string) ([]URI, error) {
 // GetFiles returns the file list of the download denoted by gid.
 // The response is a slice of Files.
 func (c *Client) GetFiles(gid string) ([]File, error) {
+	return c.GetFilesWithContext(context.Background(), gid)
+}
+
+// GetFilesWithContext is like GetFiles() but the passed context can be used to cancel the call.
+func (c *Client) GetFilesWithContext(ctx context.Context, gid string) ([]File, error) {
 	var reply []File
-	err := c.rpcClient.Call(aria2proto.GetFiles, c.getArgs(gid), &reply)
+	err := c.call(ctx, aria2proto.GetFiles, c.getArgs(gid), &reply)
 
 	return reply, err
 }
@@ -436,8 +1519,13 @@ func (c *Client) GetFiles(gid string) ([]File, error) {
 // This method is for BitTorrent only.
 // The response is a slice of Peers.
 func (c *Client) GetPeers(gid string) ([]Peer, error) {
+	return c.GetPeersWithContext(context.Background(), gid)
+}
+
+// GetPeersWithContext is like GetPeers() but the passed context can be used to cancel the call.
+func (c *Client) GetPeersWithContext(ctx context.Context, gid string) ([]Peer, error) {
 	var reply []Peer
-	err := c.rpcClient.Call(aria2proto.GetPeers, c.getArgs(gid), &reply)
+	err := c.call(ctx, aria2proto.GetPeers, c.getArgs(gid), &reply)
 
 	return reply, err
 }
@@ -445,8 +1533,13 @@ func (c *Client) GetPeers(gid string) ([]Peer, error) {
 // GetServers returns currently connected HTTP(S)/FTP/SFTP servers of the download denoted by gid.
 // Returns a slice of FileServers.
 func (c *Client) GetServers(gid string) ([]FileServers, error) {
+	return c.GetServersWithContext(context.Background(), gid)
+}
+
+// GetServersWithContext is like GetServers() but the passed context can be used to cancel the call.
+func (c *Client) GetServersWithContext(ctx context.Context, gid string) ([]FileServers, error) {
 	var reply []FileServers
-	err := c.rpcClient.Call(aria2proto.GetServers, c.getArgs(gid), &reply)
+	err := c.call(ctx, aria2proto.GetServers, c.getArgs(gid), &reply)
 
 	return reply, err
 }
@@ -454,12 +1547,83 @@ func (c *Client) GetServers(gid string) ([]FileServers, error) {
 // TellActive returns a slice of active downloads represented by their Status.
 // keys does the same as in the TellStatus() method.
 func (c *Client) TellActive(keys ...string) ([]Status, error) {
+	return c.TellActiveWithContext(context.Background(), keys...)
+}
+
+// TellActiveWithContext is like TellActive() but the passed context can be used to cancel the call.
+func (c *Client) TellActiveWithContext(ctx context.Context, keys ...string) ([]Status, error) {
 	var reply []Status
-	err := c.rpcClient.Call(aria2proto.TellActive, c.getArgs(keys), &reply)
+	err := c.call(ctx, aria2proto.TellActive, keysArgs(c.getArgs(), keys), &reply)
 
 	return reply, err
 }
 
+// StreamActive is like TellActive() but sends the active downloads to the returned status channel.
+// See StreamActiveWithContext() for details.
+func (c *Client) StreamActive(keys ...string) (<-chan Status, <-chan error) {
+	return c.StreamActiveWithContext(context.Background(), keys...)
+}
+
+// StreamActiveWithContext is like TellActiveWithContext() but sends the active downloads
+// to the returned status channel.
+// The statuses are decoded one at a time while they're received from the channel,
+// so only one Status is held in memory instead of a slice of all of them.
+// The response of aria2 itself is still received as a whole.
+//
+// If the call fails or ctx is done, the error is sent to the error channel.
+// Both channels are closed once all statuses were sent or an error occurred.
+func (c *Client) StreamActiveWithContext(ctx context.Context, keys ...string) (<-chan Status, <-chan error) {
+	statuses := make(chan Status)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(statuses)
+
+		var reply json.RawMessage
+		if err := c.call(ctx, aria2proto.TellActive, keysArgs(c.getArgs(), keys), &reply); err != nil {
+			errs <- err
+			return
+		}
+
+		if err := decodeStatuses(ctx, reply, statuses); err != nil {
+			errs <- err
+		}
+	}()
+
+	return statuses, errs
+}
+
+// decodeStatuses decodes the JSON array of statuses in data one element at a time
+// and sends each of them to statuses.
+func decodeStatuses(ctx context.Context, data []byte, statuses chan<- Status) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	if token != json.Delim('[') {
+		return fmt.Errorf("expected an array of statuses, got %v", token)
+	}
+
+	for decoder.More() {
+		var status Status
+		if err := decoder.Decode(&status); err != nil {
+			return err
+		}
+
+		select {
+		case statuses <- status:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
 // TellWaiting returns a slice of waiting downloads including paused ones represented by their Status.
 //
 // offset is an integer and specifies the offset from the download waiting at the front.
@@ -471,29 +1635,84 @@ func (c *Client) TellActive(keys ...string) ([]Status, error) {
 //
 // If specified, the returned Statuses only contain the keys passed to the method.
 func (c *Client) TellWaiting(offset int, num uint, keys ...string) ([]Status, error) {
+	return c.TellWaitingWithContext(context.Background(), offset, num, keys...)
+}
+
+// TellWaitingWithContext is like TellWaiting() but the passed context can be used to cancel the call.
+func (c *Client) TellWaitingWithContext(ctx context.Context, offset int, num uint, keys ...string) ([]Status, error) {
 	var reply []Status
-	err := c.rpcClient.Call(aria2proto.TellWaiting, c.getArgs(offset, num, keys), &reply)
+	err := c.call(ctx, aria2proto.TellWaiting, keysArgs(c.getArgs(offset, num), keys), &reply)
 
 	return reply, err
 }
 
 // TellStopped returns a slice of stopped downloads represented by their Status.
 //
-// offset is an integer and specifies the offset from the download waiting at the front.
+// offset is an integer and specifies the offset from the oldest stopped download.
 // num is an integer and specifies the max. number of downloads to be returned.
 //
 // If offset is a positive integer, this method returns downloads in the range of [offset, offset + num).
-// offset can be a negative integer. offset == -1 points last download in the waiting queue and offset == -2 points to
+// offset can be a negative integer. offset == -1 points to the most recently stopped download and offset == -2 points to
 // the download before the last download, and so on. The returned statuses are in reversed order then.
 //
 // If specified, the returned Statuses only contain the keys passed to the method.
 func (c *Client) TellStopped(offset int, num uint, keys ...string) ([]Status, error) {
+	return c.TellStoppedWithContext(context.Background(), offset, num, keys...)
+}
+
+// TellStoppedWithContext is like TellStopped() but the passed context can be used to cancel the call.
+func (c *Client) TellStoppedWithContext(ctx context.Context, offset int, num uint, keys ...string) ([]Status, error) {
 	var reply []Status
-	err := c.rpcClient.Call(aria2proto.TellStopped, c.getArgs(offset, num, keys), &reply)
+	err := c.call(ctx, aria2proto.TellStopped, keysArgs(c.getArgs(offset, num), keys), &reply)
 
 	return reply, err
 }
 
+// tellPageSize is the number of downloads requested at once when paging through a queue.
+const tellPageSize = 1000
+
+// tellAll pages through all downloads returned by the tell method.
+func (c *Client) tellAll(ctx context.Context, method string, keys []string) ([]Status, error) {
+	var statuses []Status
+
+	for offset := 0; ; offset += tellPageSize {
+		var page []Status
+		err := c.call(ctx, method, keysArgs(c.getArgs(offset, tellPageSize), keys), &page)
+		if err != nil {
+			return statuses, err
+		}
+
+		statuses = append(statuses, page...)
+		if len(page) < tellPageSize {
+			return statuses, nil
+		}
+	}
+}
+
+// TellAllWaiting returns all waiting downloads including paused ones
+// by paging through the queue using TellWaiting().
+// keys does the same as in the TellStatus() method.
+func (c *Client) TellAllWaiting(keys ...string) ([]Status, error) {
+	return c.TellAllWaitingWithContext(context.Background(), keys...)
+}
+
+// TellAllWaitingWithContext is like TellAllWaiting() but the passed context can be used to cancel the call.
+func (c *Client) TellAllWaitingWithContext(ctx context.Context, keys ...string) ([]Status, error) {
+	return c.tellAll(ctx, aria2proto.TellWaiting, keys)
+}
+
+// TellAllStopped returns all stopped downloads
+// by paging through them using TellStopped().
+// keys does the same as in the TellStatus() method.
+func (c *Client) TellAllStopped(keys ...string) ([]Status, error) {
+	return c.TellAllStoppedWithContext(context.Background(), keys...)
+}
+
+// TellAllStoppedWithContext is like TellAllStopped() but the passed context can be used to cancel the call.
+func (c *Client) TellAllStoppedWithContext(ctx context.Context, keys ...string) ([]Status, error) {
+	return c.tellAll(ctx, aria2proto.TellStopped, keys)
+}
+
 // PositionSetBehaviour determines how a position is to be interpreted
 type PositionSetBehaviour string
 
@@ -508,23 +1727,42 @@ const (
 	SetPositionRelative PositionSetBehaviour = "POS_CUR"
 )
 
+// IsValid reports whether the behaviour is one of SetPositionStart, SetPositionEnd and SetPositionRelative.
+func (b PositionSetBehaviour) IsValid() bool {
+	switch b {
+	case SetPositionStart, SetPositionEnd, SetPositionRelative:
+		return true
+	default:
+		return false
+	}
+}
+
 // ChangePosition changes the position of the download denoted by gid in the queue.
 //
 // If how is SetPositionStart, it moves the download to a position relative to the beginning of the queue.
 // If how is SetPositionRelative, it moves the download to a position relative to the current position.
 // If how is SetPositionEnd, it moves the download to a position relative to the end of the queue.
+// pos may be negative for SetPositionRelative and SetPositionEnd to move the download towards
+// the beginning of the queue.
 // If the destination position is less than 0 or beyond the end of the queue,
 // it moves the download to the beginning or the end of the queue respectively.
+// If how isn't one of the above, ErrInvalidPositionBehaviour is returned without calling aria2.
 //
 // The response is an integer denoting the resulting position.
 func (c *Client) ChangePosition(gid string, pos int, how PositionSetBehaviour) (int, error) {
-	args := c.getArgs(gid, pos)
-	if how != "" {
-		args = append(args, how)
+	return c.ChangePositionWithContext(context.Background(), gid, pos, how)
+}
+
+// ChangePositionWithContext is like ChangePosition() but the passed context can be used to cancel the call.
+func (c *Client) ChangePositionWithContext(ctx context.Context, gid string, pos int, how PositionSetBehaviour) (int, error) {
+	if !how.IsValid() {
+		return 0, ErrInvalidPositionBehaviour
 	}
 
+	args := c.getArgs(gid, pos, how)
+
 	var reply int
-	err := c.rpcClient.Call(aria2proto.ChangePosition, args, &reply)
+	err := c.call(ctx, aria2proto.ChangePosition, args, &reply)
 
 	return reply, err
 }
@@ -542,10 +1780,15 @@ func (c *Client) ChangePosition(gid string, pos int, how PositionSetBehaviour) (
 // The first integer is the number of URIs deleted.
 // The second integer is the number of URIs added.
 func (c *Client) ChangeURIAt(gid string, fileIndex uint, delURIs []string, addURIs []string, position uint) (uint, uint, error) {
-	args := c.getArgs(gid, fileIndex, delURIs, addURIs, position)
+	return c.ChangeURIAtWithContext(context.Background(), gid, fileIndex, delURIs, addURIs, position)
+}
 
-	var reply []uint
-	err := c.rpcClient.Call(aria2proto.ChangeURI, args, &reply)
+// ChangeURIAtWithContext is like ChangeURIAt() but the passed context can be used to cancel the call.
+func (c *Client) ChangeURIAtWithContext(ctx context.Context, gid string, fileIndex uint, delURIs []string, addURIs []string, position uint) (uint, uint, error) {
+	args := c.getArgs(gid, fileIndex, stringSlice(delURIs), stringSlice(addURIs), position)
+
+	var reply [2]uint
+	err := c.call(ctx, aria2proto.ChangeURI, args, &reply)
 
 	return reply[0], reply[1], err
 }
@@ -553,21 +1796,25 @@ func (c *Client) ChangeURIAt(gid string, fileIndex uint, delURIs []string, addUR
 // ChangeURI removes the URIs in delUris from and appends the URIs in addUris to download denoted by gid.
 // A download can contain multiple files and URIs are attached to each file.
 // fileIndex is used to select which file to remove/attach given URIs. fileIndex is 1-based.
-// position is used to specify where URIs are inserted in the existing waiting URI list. position is 0-based.
-// URIs are appended to the back of the list.
+// URIs are appended to the back of the list. Use ChangeURIAt() to insert them at a specific position.
+// Both delURIs and addURIs may be nil.
 //
 // This method first executes the removal and then the addition.
-// position is the position after URIs are removed, not the position when this method is called.
 // When removing an URI, if the same URIs exist in download, only one of them is removed for each URI in delUris.
 //
 // Returns two integers.
 // The first integer is the number of URIs deleted.
 // The second integer is the number of URIs added.
 func (c *Client) ChangeURI(gid string, fileIndex uint, delURIs []string, addURIs []string) (uint, uint, error) {
-	args := c.getArgs(gid, fileIndex, delURIs, addURIs)
+	return c.ChangeURIWithContext(context.Background(), gid, fileIndex, delURIs, addURIs)
+}
 
-	var reply []uint
-	err := c.rpcClient.Call(aria2proto.ChangeURI, args, &reply)
+// ChangeURIWithContext is like ChangeURI() but the passed context can be used to cancel the call.
+func (c *Client) ChangeURIWithContext(ctx context.Context, gid string, fileIndex uint, delURIs []string, addURIs []string) (uint, uint, error) {
+	args := c.getArgs(gid, fileIndex, stringSlice(delURIs), stringSlice(addURIs))
+
+	var reply [2]uint
+	err := c.call(ctx, aria2proto.ChangeURI, args, &reply)
 
 	return reply[0], reply[1], err
 }
@@ -576,8 +1823,13 @@ func (c *Client) ChangeURI(gid string, fileIndex uint, delURIs []string, addURIs
 // Note that this method does not return options which have no default value and have not been set on the command-line,
 // in configuration files or RPC methods.
 func (c *Client) GetOptions(gid string) (Options, error) {
+	return c.GetOptionsWithContext(context.Background(), gid)
+}
+
+// GetOptionsWithContext is like GetOptions() but the passed context can be used to cancel the call.
+func (c *Client) GetOptionsWithContext(ctx context.Context, gid string) (Options, error) {
 	var reply Options
-	err := c.rpcClient.Call(aria2proto.GetOptions, c.getArgs(gid), &reply)
+	err := c.call(ctx, aria2proto.GetOptions, c.getArgs(gid), &reply)
 
 	return reply, err
 }
@@ -586,22 +1838,44 @@ func (c *Client) GetOptions(gid string) (Options, error) {
 //
 // Except for following options, all options are available:
 // 	- DryRun
-//  - MetalinkBaseURI
-//  - ParameterizedURI
-//  - Pause
-//  - PieceLength
-//  - RPCSaveUploadMetadata
+// 	- MetalinkBaseURI
+// 	- ParameterizedURI
+// 	- Pause
+// 	- PieceLength
+// 	- RPCSaveUploadMetadata
+//
+// Trying to change any of them makes aria2 reject the call and its error is returned.
 //
 // Except for the following options, changing the other options of active download makes it restart
 // (restart itself is managed by aria2, and no user intervention is required):
-// 	- BtMaxPeers
-// 	- BtRequestPeerSpeedLimit
-// 	- BtRemoveUnselectedFile
+// 	- BTMaxPeers
+// 	- BTRequestPeerSpeedLimit
+// 	- BTRemoveUnselectedFile
 // 	- ForceSave
 // 	- MaxDownloadLimit
 // 	- MaxUploadLimit
 func (c *Client) ChangeOptions(gid string, options Options) error {
-	return c.rpcClient.Call(aria2proto.ChangeOptions, c.getArgs(gid, options), nil)
+	return c.ChangeOptionsWithContext(context.Background(), gid, options)
+}
+
+// ChangeOptionsWithContext is like ChangeOptions() but the passed context can be used to cancel the call.
+func (c *Client) ChangeOptionsWithContext(ctx context.Context, gid string, options Options) error {
+	return c.call(ctx, aria2proto.ChangeOptions, c.getArgs(gid, options), nil)
+}
+
+// ChangeOptionsMany changes options of all downloads denoted by gids like ChangeOptions(),
+// using a single MultiCall.
+// It returns an error for each gid, in the same order, which is nil if the change succeeded.
+// aria2 applies the changes independently, a rejected change doesn't undo the other ones.
+// The second error is returned if the MultiCall itself failed.
+// If gids is empty, no call is made and both return values are nil.
+func (c *Client) ChangeOptionsMany(gids []GID, options Options) ([]error, error) {
+	return c.ChangeOptionsManyWithContext(context.Background(), gids, options)
+}
+
+// ChangeOptionsManyWithContext is like ChangeOptionsMany() but the passed context can be used to cancel the call.
+func (c *Client) ChangeOptionsManyWithContext(ctx context.Context, gids []GID, options Options) ([]error, error) {
+	return c.batchCall(ctx, aria2proto.ChangeOptions, gids, options)
 }
 
 // GetGlobalOptions returns the global options.
@@ -611,8 +1885,13 @@ func (c *Client) ChangeOptions(gid string, options Options) error {
 // Because global options are used as a template for the options of newly added downloads,
 // the response contains keys returned by the GetOption() method.
 func (c *Client) GetGlobalOptions() (Options, error) {
+	return c.GetGlobalOptionsWithContext(context.Background())
+}
+
+// GetGlobalOptionsWithContext is like GetGlobalOptions() but the passed context can be used to cancel the call.
+func (c *Client) GetGlobalOptionsWithContext(ctx context.Context) (Options, error) {
 	var reply Options
-	err := c.rpcClient.Call(aria2proto.GetGlobalOptions, c.getArgs(), &reply)
+	err := c.call(ctx, aria2proto.GetGlobalOptions, c.getArgs(), &reply)
 
 	return reply, err
 }
@@ -647,83 +1926,528 @@ func (c *Client) GetGlobalOptions() (Options, error) {
 // To stop logging, specify an empty string as the parameter value.
 // Note that log file is always opened in append mode.
 func (c *Client) ChangeGlobalOptions(options Options) error {
-	return c.rpcClient.Call(aria2proto.ChangeGlobalOptions, c.getArgs(options), nil)
+	return c.ChangeGlobalOptionsWithContext(context.Background(), options)
+}
+
+// ChangeGlobalOptionsWithContext is like ChangeGlobalOptions() but the passed context can be used to cancel the call.
+func (c *Client) ChangeGlobalOptionsWithContext(ctx context.Context, options Options) error {
+	return c.call(ctx, aria2proto.ChangeGlobalOptions, c.getArgs(options), nil)
+}
+
+// OptionError is returned when aria2 rejects the value of an option.
+type OptionError struct {
+	Option string // Name of the option
+	Value  string // Rejected value
+	Err    error  // Error returned by aria2
+}
+
+func (e *OptionError) Error() string {
+	return fmt.Sprintf("aria2 rejected value %q for option %s: %v", e.Value, e.Option, e.Err)
+}
+
+// Unwrap returns the error returned by aria2.
+func (e *OptionError) Unwrap() error {
+	return e.Err
+}
+
+// SetMaxConcurrentDownloads changes the maximum number of parallel downloads.
+// n must be at least 1.
+// If aria2 rejects the change, an *OptionError is returned.
+func (c *Client) SetMaxConcurrentDownloads(n int) error {
+	return c.SetMaxConcurrentDownloadsWithContext(context.Background(), n)
+}
+
+// SetMaxConcurrentDownloadsWithContext is like SetMaxConcurrentDownloads() but the passed context can be used to cancel the call.
+func (c *Client) SetMaxConcurrentDownloadsWithContext(ctx context.Context, n int) error {
+	if n < 1 {
+		return fmt.Errorf("max concurrent downloads must be at least 1, got %d", n)
+	}
+
+	value := strconv.Itoa(n)
+	options := Options{Extra: map[string]string{aria2proto.OptMaxConcurrentDownloads: value}}
+
+	err := c.ChangeGlobalOptionsWithContext(ctx, options)
+	if rpcErr, ok := err.(*RPCError); ok {
+		return &OptionError{Option: aria2proto.OptMaxConcurrentDownloads, Value: value, Err: rpcErr}
+	}
+
+	return err
 }
 
 // GetGlobalStats returns global statistics such as the overall download and upload speeds.
 func (c *Client) GetGlobalStats() (Stats, error) {
+	return c.GetGlobalStatsWithContext(context.Background())
+}
+
+// GetGlobalStatsWithContext is like GetGlobalStats() but the passed context can be used to cancel the call.
+func (c *Client) GetGlobalStatsWithContext(ctx context.Context) (Stats, error) {
 	var reply Stats
-	err := c.rpcClient.Call(aria2proto.GetGlobalStats, c.getArgs(), &reply)
+	err := c.call(ctx, aria2proto.GetGlobalStats, c.getArgs(), &reply)
 
 	return reply, err
 }
 
 // PurgeDownloadResults purges completed/error/removed downloads to free memory
 func (c *Client) PurgeDownloadResults() error {
-	return c.rpcClient.Call(aria2proto.PurgeDownloadResults, c.getArgs(), nil)
+	return c.PurgeDownloadResultsWithContext(context.Background())
+}
+
+// PurgeDownloadResultsWithContext is like PurgeDownloadResults() but the passed context can be used to cancel the call.
+func (c *Client) PurgeDownloadResultsWithContext(ctx context.Context) error {
+	return c.call(ctx, aria2proto.PurgeDownloadResults, c.getArgs(), nil)
 }
 
 // RemoveDownloadResult removes a completed/error/removed download denoted by gid from memory.
 func (c *Client) RemoveDownloadResult(gid string) error {
-	return c.rpcClient.Call(aria2proto.RemoveDownloadResult, c.getArgs(gid), nil)
+	return c.RemoveDownloadResultWithContext(context.Background(), gid)
+}
+
+// RemoveDownloadResultWithContext is like RemoveDownloadResult() but the passed context can be used to cancel the call.
+func (c *Client) RemoveDownloadResultWithContext(ctx context.Context, gid string) error {
+	return c.call(ctx, aria2proto.RemoveDownloadResult, c.getArgs(gid), nil)
 }
 
 // GetVersion returns the version of aria2 and the list of enabled features.
 func (c *Client) GetVersion() (VersionInfo, error) {
+	return c.GetVersionWithContext(context.Background())
+}
+
+// GetVersionWithContext is like GetVersion() but the passed context can be used to cancel the call.
+func (c *Client) GetVersionWithContext(ctx context.Context) (VersionInfo, error) {
 	var reply VersionInfo
-	err := c.rpcClient.Call(aria2proto.GetVersion, c.getArgs(), &reply)
+	err := c.call(ctx, aria2proto.GetVersion, c.getArgs(), &reply)
 
 	return reply, err
 }
 
 // GetSessionInfo returns session information.
 func (c *Client) GetSessionInfo() (SessionInfo, error) {
+	return c.GetSessionInfoWithContext(context.Background())
+}
+
+// GetSessionInfoWithContext is like GetSessionInfo() but the passed context can be used to cancel the call.
+func (c *Client) GetSessionInfoWithContext(ctx context.Context) (SessionInfo, error) {
 	var reply SessionInfo
-	err := c.rpcClient.Call(aria2proto.GetSessionInfo, c.getArgs(), &reply)
+	err := c.call(ctx, aria2proto.GetSessionInfo, c.getArgs(), &reply)
 
 	return reply, err
 }
 
+// Ping checks whether aria2 is reachable and responds to calls.
+// It returns nil if aria2 answered a cheap call successfully.
+// The call is authenticated, so a wrong secret token makes Ping fail as well.
+// This is meant for health checks, use a context with a deadline to limit how long it may take.
+func (c *Client) Ping() error {
+	return c.PingWithContext(context.Background())
+}
+
+// PingWithContext is like Ping() but the passed context can be used to cancel the call.
+func (c *Client) PingWithContext(ctx context.Context) error {
+	_, err := c.GetVersionWithContext(ctx)
+	return err
+}
+
 // Shutdown shuts down aria2.
+// aria2 may close the connection before its response arrives.
+// This is treated as success.
 func (c *Client) Shutdown() error {
-	return c.rpcClient.Call(aria2proto.Shutdown, c.getArgs(), nil)
+	return c.ShutdownWithContext(context.Background())
+}
+
+// ShutdownWithContext is like Shutdown() but the passed context can be used to cancel the call.
+func (c *Client) ShutdownWithContext(ctx context.Context) error {
+	return c.callShutdown(ctx, aria2proto.Shutdown)
 }
 
 // ForceShutdown shuts down aria2.
 // Behaves like the Shutdown() method but doesn't perform any actions which take time,
 // such as contacting BitTorrent trackers to unregister downloads first.
 func (c *Client) ForceShutdown() error {
-	return c.rpcClient.Call(aria2proto.ForceShutdown, c.getArgs(), nil)
+	return c.ForceShutdownWithContext(context.Background())
+}
+
+// ForceShutdownWithContext is like ForceShutdown() but the passed context can be used to cancel the call.
+func (c *Client) ForceShutdownWithContext(ctx context.Context) error {
+	return c.callShutdown(ctx, aria2proto.ForceShutdown)
+}
+
+// callShutdown calls one of the shutdown methods.
+// Losing the connection after the request was sent means that aria2 exited.
+func (c *Client) callShutdown(ctx context.Context, method string) error {
+	if conn, ok := c.getConn().(interface{ Err() error }); ok {
+		if err := conn.Err(); err != nil {
+			return err
+		}
+	}
+
+	err := c.call(ctx, method, c.getArgs(), nil)
+	if err == ErrConnectionLost {
+		return nil
+	}
+
+	return err
 }
 
 // SaveSession saves the current session to a file specified by the SaveSession option.
 func (c *Client) SaveSession() error {
-	return c.rpcClient.Call(aria2proto.SaveSession, c.getArgs(), nil)
+	return c.SaveSessionWithContext(context.Background())
+}
+
+// SaveSessionWithContext is like SaveSession() but the passed context can be used to cancel the call.
+func (c *Client) SaveSessionWithContext(ctx context.Context) error {
+	return c.call(ctx, aria2proto.SaveSession, c.getArgs(), nil)
 }
 
 // MultiCall executes multiple method calls in one request.
 // Returns a MethodResult for each MethodCall in order.
+//
+// aria2 executes the calls in order and returns their results in the same order.
+// A call which failed occupies its slot with a MethodResult whose Error is set,
+// the results of the following calls aren't shifted.
+// So the i-th result always belongs to the i-th MethodCall.
+// If aria2 returns a different number of results, an error is returned instead.
+//
+// The secret token is added to the parameters of each MethodCall automatically.
 func (c *Client) MultiCall(methods ...*MethodCall) ([]MethodResult, error) {
+	return c.MultiCallWithContext(context.Background(), methods...)
+}
+
+// MultiCallWithContext is like MultiCall() but the passed context can be used to cancel the call.
+func (c *Client) MultiCallWithContext(ctx context.Context, methods ...*MethodCall) ([]MethodResult, error) {
+	calls := make([]*MethodCall, len(methods))
+	for i, method := range methods {
+		calls[i] = &MethodCall{MethodName: method.MethodName, Params: c.getMethodArgs(method.MethodName, method.Params)}
+	}
+
 	var rawResults []json.RawMessage
-	err := c.rpcClient.Call(aria2proto.Multicall, c.getArgs(methods), &rawResults)
+	if err := c.call(ctx, aria2proto.Multicall, []interface{}{calls}, &rawResults); err != nil {
+		return nil, err
+	}
+
+	if len(rawResults) != len(calls) {
+		return nil, fmt.Errorf("aria2 returned %d results for %d calls", len(rawResults), len(calls))
+	}
+
+	return parseMethodResults(rawResults), nil
+}
+
+// Call calls an arbitrary aria2 method and unmarshals its result into reply.
+// This can be used to call methods which aren't wrapped by the client.
+// The secret token is added to params automatically.
+// If reply is nil, the result is discarded.
+func (c *Client) Call(method string, params []interface{}, reply interface{}) error {
+	return c.CallWithContext(context.Background(), method, params, reply)
+}
+
+// CallWithContext is like Call() but the passed context can be used to cancel the call.
+func (c *Client) CallWithContext(ctx context.Context, method string, params []interface{}, reply interface{}) error {
+	return c.call(ctx, method, c.getMethodArgs(method, params), reply)
+}
+
+// CallWithID is like CallWithContext() but also returns the JSON-RPC id of the request.
+// The id can be used to correlate the call with the frames passed to the logger set using WithLogger().
+// If the call was retried, the id of the last attempt is returned.
+// The id is empty if the call wasn't sent or the client doesn't use a WebSocket connection.
+func (c *Client) CallWithID(ctx context.Context, method string, params []interface{}, reply interface{}) (string, error) {
+	var id string
+	ctx = wsrpc.WithIDReporter(ctx, func(callID string) {
+		id = callID
+	})
+
+	err := c.CallWithContext(ctx, method, params, reply)
+	return id, err
+}
+
+// ListMethods returns the names of all the methods aria2 supports.
+// This can be used to check whether a method is available before calling it.
+func (c *Client) ListMethods() ([]string, error) {
+	return c.ListMethodsWithContext(context.Background())
+}
+
+// ListMethodsWithContext is like ListMethods() but the passed context can be used to cancel the call.
+func (c *Client) ListMethodsWithContext(ctx context.Context) ([]string, error) {
+	var reply []string
+	err := c.call(ctx, aria2proto.ListMethods, nil, &reply)
+
+	return reply, err
+}
+
+// ListNotifications returns the names of all the notifications aria2 supports.
+func (c *Client) ListNotifications() ([]string, error) {
+	return c.ListNotificationsWithContext(context.Background())
+}
+
+// ListNotificationsWithContext is like ListNotifications() but the passed context can be used to cancel the call.
+func (c *Client) ListNotificationsWithContext(ctx context.Context) ([]string, error) {
+	var reply []string
+	err := c.call(ctx, aria2proto.ListNotifications, nil, &reply)
+
+	return reply, err
+}
+
+// BatchTellStatus returns the status of every download denoted by gids using a single MultiCall.
+// The statuses are in the same order as gids.
+// keys does the same as in the TellStatus() method.
+//
+// If aria2 returned an error for some of the gids, the corresponding statuses are left empty
+// and the first of these errors is returned.
+func (c *Client) BatchTellStatus(gids []string, keys ...string) ([]Status, error) {
+	return c.BatchTellStatusWithContext(context.Background(), gids, keys...)
+}
+
+// BatchTellStatusWithContext is like BatchTellStatus() but the passed context can be used to cancel the call.
+func (c *Client) BatchTellStatusWithContext(ctx context.Context, gids []string, keys ...string) ([]Status, error) {
+	methods := make([]*MethodCall, len(gids))
+	for i, gid := range gids {
+		methods[i] = NewMethodCall(aria2proto.TellStatus, keysArgs([]interface{}{gid}, keys)...)
+	}
+
+	results, err := c.MultiCallWithContext(ctx, methods...)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(results))
+	for i := range results {
+		if resultErr := results[i].Unmarshal(&statuses[i]); resultErr != nil && err == nil {
+			err = resultErr
+		}
+	}
+
+	return statuses, err
+}
+
+// AddURIJob describes a download added by the AddURIs() method.
+type AddURIJob struct {
+	URIs    []string // URIs pointing to the same resource, see AddURI()
+	Options *Options // Options of the download, may be nil
+}
+
+// AddURIs adds a new download for every job using a single MultiCall.
+// It returns the GID of every new download and the error aria2 reported for every job,
+// both in the same order as jobs.
+// If a job failed, its GID is empty and its error is set.
+// Jobs whose GID option is invalid aren't sent to aria2, their error is ErrInvalidGID.
+// The third return value is only set if the MultiCall itself failed.
+func (c *Client) AddURIs(jobs []AddURIJob) ([]GID, []error, error) {
+	return c.AddURIsWithContext(context.Background(), jobs)
+}
+
+// AddURIsWithContext is like AddURIs() but the passed context can be used to cancel the call.
+func (c *Client) AddURIsWithContext(ctx context.Context, jobs []AddURIJob) ([]GID, []error, error) {
+	gids := make([]GID, len(jobs))
+	errs := make([]error, len(jobs))
+
+	// indices of the jobs which are sent to aria2, in the order of methods
+	var sent []int
+	var methods []*MethodCall
+	for i, job := range jobs {
+		if errs[i] = validateGIDOption(job.Options); errs[i] != nil {
+			continue
+		}
+
+		args := []interface{}{stringSlice(job.URIs)}
+		if job.Options != nil {
+			args = append(args, job.Options)
+		}
+
+		sent = append(sent, i)
+		methods = append(methods, NewMethodCall(aria2proto.AddURI, args...))
+	}
+
+	if len(methods) == 0 {
+		return gids, errs, nil
+	}
+
+	results, err := c.MultiCallWithContext(ctx, methods...)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	results := make([]MethodResult, len(rawResults))
+	for i, job := range sent {
+		var gid string
+		if errs[job] = duplicateDownloadError(results[i].Unmarshal(&gid)); errs[job] == nil {
+			gids[job] = c.GetGID(gid)
+		}
+	}
+
+	return gids, errs, nil
+}
+
+// tellEverything returns the statuses of the active, waiting and stopped downloads, in this order.
+func (c *Client) tellEverything(ctx context.Context, keys ...string) ([]Status, error) {
+	var statuses []Status
+	for _, tell := range []func(context.Context, ...string) ([]Status, error){
+		c.TellActiveWithContext,
+		c.TellAllWaitingWithContext,
+		c.TellAllStoppedWithContext,
+	} {
+		page, err := tell(ctx, keys...)
+		if err != nil {
+			return nil, err
+		}
+
+		statuses = append(statuses, page...)
+	}
+
+	return statuses, nil
+}
+
+// FindByGIDPrefix returns the statuses of the active, waiting and stopped downloads
+// whose GID starts with prefix.
+// This is useful for downloads which were added using structured GIDs (see Options.GID).
+// To remove these downloads, pass a predicate using strings.HasPrefix to RemoveWhere().
+//
+// Keys limit the fields of the returned statuses just like for TellStatus(),
+// the GID is always requested.
+func (c *Client) FindByGIDPrefix(prefix string, keys ...string) ([]Status, error) {
+	return c.FindByGIDPrefixWithContext(context.Background(), prefix, keys...)
+}
+
+// FindByGIDPrefixWithContext is like FindByGIDPrefix() but the passed context can be used to cancel the call.
+func (c *Client) FindByGIDPrefixWithContext(ctx context.Context, prefix string, keys ...string) ([]Status, error) {
+	if len(keys) > 0 {
+		keys = append([]string{"gid"}, keys...)
+	}
+
+	statuses, err := c.tellEverything(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	// aria2 formats GIDs using lowercase hex digits
+	prefix = strings.ToLower(prefix)
+
+	matching := []Status{}
+	for _, status := range statuses {
+		if strings.HasPrefix(status.GID, prefix) {
+			matching = append(matching, status)
+		}
+	}
+
+	return matching, nil
+}
+
+// RemoveWhere removes every download whose status matches pred.
+// It lists the active, waiting and stopped downloads and removes the matching ones using a single MultiCall.
+// Active and waiting downloads are removed using Remove(),
+// the results of stopped downloads using RemoveDownloadResult().
+//
+// It returns the GIDs of the removed downloads.
+// If aria2 failed to remove some of the downloads, the first of these errors is returned as well.
+func (c *Client) RemoveWhere(pred func(status Status) bool) ([]GID, error) {
+	return c.RemoveWhereWithContext(context.Background(), pred)
+}
+
+// RemoveWhereWithContext is like RemoveWhere() but the passed context can be used to cancel the call.
+func (c *Client) RemoveWhereWithContext(ctx context.Context, pred func(status Status) bool) ([]GID, error) {
+	statuses, err := c.tellEverything(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var gids []string
+	var methods []*MethodCall
+	for _, status := range statuses {
+		if !pred(status) {
+			continue
+		}
+
+		method := aria2proto.Remove
+		if isTerminalStatus(status.Status) {
+			method = aria2proto.RemoveDownloadResult
+		}
+
+		gids = append(gids, status.GID)
+		methods = append(methods, NewMethodCall(method, status.GID))
+	}
 
-	for i, rawResult := range rawResults {
-		var methodResult []byte
+	if len(methods) == 0 {
+		return []GID{}, nil
+	}
 
-		var methodErr MethodCallError
-		_ = json.Unmarshal(rawResult, &methodErr)
+	results, err := c.MultiCallWithContext(ctx, methods...)
+	if err != nil {
+		return nil, err
+	}
 
-		if methodErr == (MethodCallError{}) {
-			var resultArray [1]json.RawMessage
-			_ = json.Unmarshal(rawResult, &resultArray)
+	removed := make([]GID, 0, len(results))
+	var firstErr error
+	for i, result := range results {
+		if result.Error != nil {
+			if firstErr == nil {
+				firstErr = result.Error
+			}
 
-			methodResult = resultArray[0]
+			continue
 		}
 
-		results[i] = MethodResult{Result: methodResult, Error: &methodErr}
+		removed = append(removed, c.GetGID(gids[i]))
+	}
+
+	return removed, firstErr
+}
+
+// batchCall calls method for every gid using a single MultiCall.
+// args are passed to every call after the gid.
+// It returns the error aria2 reported for each gid, nil if the call succeeded.
+func (c *Client) batchCall(ctx context.Context, method string, gids []GID, args ...interface{}) ([]error, error) {
+	if len(gids) == 0 {
+		return nil, nil
+	}
+
+	methods := make([]*MethodCall, len(gids))
+	for i, gid := range gids {
+		methods[i] = NewMethodCall(method, append([]interface{}{gid.GID}, args...)...)
+	}
+
+	results, err := c.MultiCallWithContext(ctx, methods...)
+	if err != nil {
+		return nil, err
+	}
+
+	errs := make([]error, len(results))
+	for i := range results {
+		errs[i] = results[i].Error
 	}
 
-	return results, err
+	return errs, nil
+}
+
+// RemoveAll removes every download denoted by gids using a single MultiCall.
+// It returns a slice containing the error aria2 reported for each gid in the same order as gids.
+// An element is nil if the download was removed.
+// The second return value is only set if the MultiCall itself failed.
+// If gids is empty, no call is made and both return values are nil.
+//
+// Unlike PauseAll(), RemoveAll only affects the given downloads.
+func (c *Client) RemoveAll(gids []GID) ([]error, error) {
+	return c.RemoveAllWithContext(context.Background(), gids)
+}
+
+// RemoveAllWithContext is like RemoveAll() but the passed context can be used to cancel the call.
+func (c *Client) RemoveAllWithContext(ctx context.Context, gids []GID) ([]error, error) {
+	return c.batchCall(ctx, aria2proto.Remove, gids)
+}
+
+// PauseMany pauses every download denoted by gids using a single MultiCall.
+// The return values are the same as for the RemoveAll() method.
+func (c *Client) PauseMany(gids []GID) ([]error, error) {
+	return c.PauseManyWithContext(context.Background(), gids)
+}
+
+// PauseManyWithContext is like PauseMany() but the passed context can be used to cancel the call.
+func (c *Client) PauseManyWithContext(ctx context.Context, gids []GID) ([]error, error) {
+	return c.batchCall(ctx, aria2proto.Pause, gids)
+}
+
+// UnpauseMany unpauses every download denoted by gids using a single MultiCall.
+// The return values are the same as for the RemoveAll() method.
+func (c *Client) UnpauseMany(gids []GID) ([]error, error) {
+	return c.UnpauseManyWithContext(context.Background(), gids)
+}
+
+// UnpauseManyWithContext is like UnpauseMany() but the passed context can be used to cancel the call.
+func (c *Client) UnpauseManyWithContext(ctx context.Context, gids []GID) ([]error, error) {
+	return c.batchCall(ctx, aria2proto.Unpause, gids)
 }
@@ -1,6 +1,75 @@
 package arigo
 
-import "fmt"
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startTestClient starts a WebSocket server using testHandler()
+// and returns a client connected to it.
+func startTestClient(t *testing.T, respond func(method string, params []interface{}) interface{}, options ...ClientOption) *Client {
+	server := httptest.NewServer(testHandler(respond))
+	t.Cleanup(server.Close)
+
+	client, err := Dial("ws"+strings.TrimPrefix(server.URL, "http"), "", options...)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+// testHandler returns a WebSocket handler which passes the method and params
+// of every received request to respond and replies with the returned result.
+// If the result is a *RPCError, it is sent as the error of the response.
+func testHandler(respond func(method string, params []interface{}) interface{}) http.Handler {
+	upgrader := websocket.Upgrader{}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		for {
+			var req struct {
+				ID     interface{}   `json:"id"`
+				Method string        `json:"method"`
+				Params []interface{} `json:"params"`
+			}
+			if err := ws.ReadJSON(&req); err != nil {
+				return
+			}
+
+			resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+			if result := respond(req.Method, req.Params); isError(result) {
+				resp["error"] = result
+			} else {
+				resp["result"] = result
+			}
+
+			_ = ws.WriteJSON(resp)
+		}
+	})
+}
 
 // Dial is a convenience method which connects to an aria2 RPC interface.
 // It establishes a WebSocket connection to the given url and passes it
@@ -45,3 +114,1054 @@ func ExampleClient() {
 
 	fmt.Println(status.Status)
 }
+
+func TestChangePositionInvalidBehaviour(t *testing.T) {
+	assert.True(t, SetPositionRelative.IsValid())
+	assert.False(t, PositionSetBehaviour("POS_START").IsValid())
+
+	var client Client
+	_, err := client.ChangePosition("2089b05ecca3d829", -1, "")
+	assert.Equal(t, ErrInvalidPositionBehaviour, err)
+}
+
+func isError(result interface{}) bool {
+	_, ok := result.(*RPCError)
+	return ok
+}
+
+func TestAddTorrentFile(t *testing.T) {
+	torrent := []byte("d8:announce0:e")
+	path := filepath.Join(t.TempDir(), "test.torrent")
+	require.NoError(t, ioutil.WriteFile(path, torrent, 0644))
+
+	calls := make(chan []interface{}, 1)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		calls <- params
+		return "2089b05ecca3d829"
+	})
+
+	gid, err := client.AddTorrentFile(path, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2089b05ecca3d829", gid.GID)
+	assert.Equal(t, []interface{}{base64.StdEncoding.EncodeToString(torrent), []interface{}{}}, <-calls)
+
+	_, err = client.AddTorrentFile(filepath.Join(t.TempDir(), "missing.torrent"), nil, nil)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestAddMetalinkFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.metalink")
+	require.NoError(t, ioutil.WriteFile(path, []byte("<metalink/>"), 0644))
+
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		return []string{"2089b05ecca3d829", "d2703803b52216d1"}
+	})
+
+	gids, err := client.AddMetalinkFile(path, nil)
+	require.NoError(t, err)
+	require.Len(t, gids, 2)
+	assert.Equal(t, "2089b05ecca3d829", gids[0].GID)
+	assert.Equal(t, "d2703803b52216d1", gids[1].GID)
+}
+
+func TestWithSecret(t *testing.T) {
+	calls := make(chan []interface{}, 2)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		calls <- params
+		if method == "system.multicall" {
+			return []interface{}{[]interface{}{"OK"}}
+		}
+		return "OK"
+	}, WithSecret("secret"))
+
+	require.NoError(t, client.SaveSession())
+	assert.Equal(t, []interface{}{"token:secret"}, <-calls)
+
+	_, err := client.MultiCall(NewMethodCall("aria2.saveSession"))
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{
+		[]interface{}{map[string]interface{}{"methodName": "aria2.saveSession", "params": []interface{}{"token:secret"}}},
+	}, <-calls)
+}
+
+func TestDialTLS(t *testing.T) {
+	server := httptest.NewTLSServer(testHandler(func(method string, params []interface{}) interface{} {
+		return "OK"
+	}))
+	defer server.Close()
+
+	url := "wss" + strings.TrimPrefix(server.URL, "https")
+
+	_, err := Dial(url, "")
+	assert.Error(t, err, "server certificate should be rejected")
+
+	roots := x509.NewCertPool()
+	roots.AddCert(server.Certificate())
+
+	client, err := Dial(url, "", WithTLSConfig(&tls.Config{RootCAs: roots}))
+	require.NoError(t, err)
+	defer client.Close()
+
+	assert.NoError(t, client.SaveSession())
+}
+
+func TestGracefulClose(t *testing.T) {
+	received, release := make(chan struct{}), make(chan struct{})
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		close(received)
+		<-release
+		return "OK"
+	})
+
+	sub := client.NewSubscription(0)
+
+	callErr := make(chan error)
+	go func() {
+		callErr <- client.SaveSession()
+	}()
+
+	<-received
+
+	closeErr := make(chan error)
+	go func() {
+		closeErr <- client.GracefulClose(context.Background())
+	}()
+
+	for !client.isClosed() {
+		time.Sleep(time.Millisecond)
+	}
+	assert.Equal(t, ErrClientClosed, client.SaveSession())
+
+	close(release)
+	assert.NoError(t, <-callErr)
+	assert.NoError(t, <-closeErr)
+
+	_, ok := <-sub.Started
+	assert.False(t, ok, "subscription should be closed")
+}
+
+func TestGracefulCloseTimeout(t *testing.T) {
+	received, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		close(received)
+		<-release
+		return "OK"
+	})
+
+	callErr := make(chan error)
+	go func() {
+		callErr <- client.SaveSession()
+	}()
+
+	<-received
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	assert.Equal(t, context.DeadlineExceeded, client.GracefulClose(ctx))
+	assert.Equal(t, ErrClientClosed, <-callErr)
+}
+
+func TestShutdownConnectionDropped(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+
+		// exit without responding, like aria2 sometimes does
+		var req map[string]interface{}
+		_ = ws.ReadJSON(&req)
+		_ = ws.Close()
+	}))
+	defer server.Close()
+
+	client, err := Dial("ws"+strings.TrimPrefix(server.URL, "http"), "")
+	require.NoError(t, err)
+	defer client.Close()
+
+	assert.NoError(t, client.Shutdown())
+	assert.Equal(t, ErrConnectionLost, client.ForceShutdown())
+}
+
+func TestChangeOptionsError(t *testing.T) {
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		return &RPCError{Code: 1, Message: "We don't allow changing option dry-run"}
+	})
+
+	err := client.ChangeOptions("2089b05ecca3d829", Options{DryRun: Bool(true)})
+	require.Error(t, err)
+	assert.Equal(t, "We don't allow changing option dry-run", err.Error())
+
+	var rpcErr *RPCError
+	require.True(t, errors.As(err, &rpcErr))
+	assert.Equal(t, 1, rpcErr.Code)
+}
+
+func TestAddURIMirrors(t *testing.T) {
+	calls := make(chan []interface{}, 1)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		calls <- params
+		return "2089b05ecca3d829"
+	})
+
+	gid, err := client.AddURI(URIs("http://a.example.org/file", "http://b.example.org/file"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2089b05ecca3d829", gid.GID)
+	assert.Equal(t, []interface{}{
+		[]interface{}{"http://a.example.org/file", "http://b.example.org/file"},
+	}, <-calls, "mirrors should be sent as a single array")
+}
+
+func TestWatchStatus(t *testing.T) {
+	responses := []string{"waiting", "active", "complete"}
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		status := responses[0]
+		if len(responses) > 1 {
+			responses = responses[1:]
+		}
+
+		return map[string]interface{}{"gid": "2089b05ecca3d829", "status": status}
+	})
+
+	statuses, errs := client.WatchStatus("2089b05ecca3d829", time.Millisecond)
+
+	var received []DownloadStatus
+	for status := range statuses {
+		received = append(received, status.Status)
+	}
+
+	assert.Equal(t, []DownloadStatus{StatusWaiting, StatusActive, StatusCompleted}, received)
+	assert.NoError(t, <-errs)
+}
+
+func TestWatchStatusCancel(t *testing.T) {
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		return map[string]interface{}{"gid": "2089b05ecca3d829", "status": "active"}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	statuses, errs := client.WatchStatusWithContext(ctx, "2089b05ecca3d829", time.Hour)
+
+	assert.Equal(t, StatusActive, (<-statuses).Status)
+	cancel()
+
+	assert.Equal(t, context.Canceled, <-errs)
+	_, ok := <-statuses
+	assert.False(t, ok, "status channel should be closed")
+}
+
+func TestListMethods(t *testing.T) {
+	calls := make(chan []interface{}, 2)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		calls <- params
+		if method == "system.listNotifications" {
+			return []string{"aria2.onDownloadStart"}
+		}
+
+		return []string{"aria2.addUri", "system.listMethods"}
+	}, WithSecret("secret"))
+
+	methods, err := client.ListMethods()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aria2.addUri", "system.listMethods"}, methods)
+	assert.Empty(t, <-calls, "system methods don't accept the secret token")
+
+	notifications, err := client.ListNotifications()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"aria2.onDownloadStart"}, notifications)
+	assert.Empty(t, <-calls)
+}
+
+func TestRequestTimeout(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		<-release
+		return "OK"
+	}, WithRequestTimeout(20*time.Millisecond))
+
+	assert.Equal(t, context.DeadlineExceeded, client.SaveSession())
+
+	// a deadline of the passed context wins over the default
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	assert.Equal(t, context.DeadlineExceeded, client.SaveSessionWithContext(ctx))
+	assert.True(t, time.Since(start) >= 100*time.Millisecond, "context deadline should be used")
+}
+
+func TestCall(t *testing.T) {
+	calls := make(chan []interface{}, 1)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		calls <- params
+		return map[string]interface{}{"sessionId": "cd6a3bc6a1de28eb5bfa181e5f6b916d44af31a9"}
+	}, WithSecret("secret"))
+
+	var info SessionInfo
+	require.NoError(t, client.Call("aria2.getSessionInfo", nil, &info))
+	assert.Equal(t, "cd6a3bc6a1de28eb5bfa181e5f6b916d44af31a9", info.ID)
+	assert.Equal(t, []interface{}{"token:secret"}, <-calls)
+}
+
+func TestRemoveAll(t *testing.T) {
+	calls := make(chan []interface{}, 1)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		calls <- params
+		return []interface{}{
+			[]interface{}{"2089b05ecca3d829"},
+			map[string]interface{}{"code": 1, "message": "Active Download not found for GID#d2703803b52216d1"},
+		}
+	})
+
+	errs, err := client.RemoveAll([]GID{client.GetGID("2089b05ecca3d829"), client.GetGID("d2703803b52216d1")})
+	require.NoError(t, err)
+	require.Len(t, errs, 2)
+	assert.NoError(t, errs[0])
+	assert.Equal(t, &RPCError{Code: 1, Message: "Active Download not found for GID#d2703803b52216d1"}, errs[1])
+
+	assert.Equal(t, []interface{}{[]interface{}{
+		map[string]interface{}{"methodName": "aria2.remove", "params": []interface{}{"2089b05ecca3d829"}},
+		map[string]interface{}{"methodName": "aria2.remove", "params": []interface{}{"d2703803b52216d1"}},
+	}}, <-calls)
+
+	errs, err = client.PauseMany(nil)
+	assert.NoError(t, err)
+	assert.Nil(t, errs)
+	assert.Empty(t, calls, "no call should be made for empty input")
+}
+
+func TestGetFilesMatchesTellStatus(t *testing.T) {
+	files := []interface{}{map[string]interface{}{
+		"index":           "1",
+		"path":            "/downloads/file",
+		"length":          "34896138",
+		"completedLength": "1048576",
+		"selected":        "true",
+		"uris":            []interface{}{map[string]interface{}{"uri": "http://example.org/file", "status": "used"}},
+	}}
+
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		if method == "aria2.getFiles" {
+			return files
+		}
+
+		return map[string]interface{}{"gid": "2089b05ecca3d829", "files": files}
+	})
+
+	fromGetFiles, err := client.GetFiles("2089b05ecca3d829")
+	require.NoError(t, err)
+
+	status, err := client.TellStatus("2089b05ecca3d829", "files")
+	require.NoError(t, err)
+
+	assert.Equal(t, []File{{
+		Index:           1,
+		Path:            "/downloads/file",
+		Length:          34896138,
+		CompletedLength: 1048576,
+		Selected:        true,
+		URIs:            []URI{{URI: "http://example.org/file", Status: URIUsed}},
+	}}, fromGetFiles)
+	assert.Equal(t, fromGetFiles, status.Files)
+}
+
+func TestErrors(t *testing.T) {
+	client := newClient("", []ClientOption{WithErrorBuffer(1)})
+
+	client.handleNotification("aria2.onDownloadStart", json.RawMessage(`{"gid": "2089b05ecca3d829"}`))
+	client.handleNotification("aria2.onDownloadStart", json.RawMessage(`"invalid"`))
+
+	select {
+	case err := <-client.Errors():
+		assert.Contains(t, err.Error(), "couldn't decode aria2.onDownloadStart notification")
+	default:
+		t.Fatal("no error reported")
+	}
+
+	assert.Empty(t, client.Errors(), "errors exceeding the buffer should be discarded")
+}
+
+func TestConcurrentCalls(t *testing.T) {
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		switch method {
+		case "aria2.tellStatus":
+			return map[string]interface{}{"gid": params[0], "status": "active"}
+		case "aria2.addUri":
+			return params[0].([]interface{})[0]
+		}
+
+		return nil
+	})
+
+	const goroutines = 12
+	const calls = 50
+
+	errs := make(chan error, goroutines+1)
+	for i := 0; i < goroutines; i++ {
+		go func(i int) {
+			for j := 0; j < calls; j++ {
+				gid := fmt.Sprintf("%d-%d", i, j)
+
+				status, err := client.TellStatus(gid)
+				if err == nil && status.GID != gid {
+					err = fmt.Errorf("expected status of %s, got %s", gid, status.GID)
+				}
+
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+
+			errs <- nil
+		}(i)
+	}
+
+	go func() {
+		for j := 0; j < calls; j++ {
+			uri := fmt.Sprintf("https://example.org/%d", j)
+
+			gid, err := client.AddURI(URIs(uri), nil)
+			if err == nil && gid.GID != uri {
+				err = fmt.Errorf("expected gid %s, got %s", uri, gid.GID)
+			}
+
+			if err != nil {
+				errs <- err
+				return
+			}
+		}
+
+		errs <- nil
+	}()
+
+	for i := 0; i < goroutines+1; i++ {
+		assert.NoError(t, <-errs)
+	}
+}
+
+func TestDialHeader(t *testing.T) {
+	headers := make(chan http.Header, 1)
+	handler := testHandler(func(method string, params []interface{}) interface{} {
+		return "OK"
+	})
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers <- r.Header
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	client, err := Dial("ws"+strings.TrimPrefix(server.URL, "http"), "",
+		WithHeader("Authorization", "Bearer token"),
+		WithHeader("X-Test", "a"),
+		WithHeader("X-Test", "b"),
+		WithOrigin(server.URL),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	header := <-headers
+	assert.Equal(t, "Bearer token", header.Get("Authorization"))
+	assert.Equal(t, []string{"a", "b"}, header["X-Test"])
+	assert.Equal(t, server.URL, header.Get("Origin"))
+}
+
+func TestWithLogger(t *testing.T) {
+	respond := func(method string, params []interface{}) interface{} {
+		return "OK"
+	}
+
+	type frame struct {
+		dir  Direction
+		data string
+	}
+
+	frames := make(chan frame, 2)
+	logger := func(dir Direction, data []byte) {
+		frames <- frame{dir, string(data)}
+	}
+
+	client := startTestClient(t, respond, WithSecret("s3cret"), WithLogger(logger))
+	require.NoError(t, client.SaveSession())
+
+	sent := <-frames
+	assert.Equal(t, DirectionSent, sent.dir)
+	assert.Contains(t, sent.data, `"params":["token:[REDACTED]"]`)
+	assert.NotContains(t, sent.data, "s3cret")
+
+	received := <-frames
+	assert.Equal(t, DirectionReceived, received.dir)
+	assert.Contains(t, received.data, `"result":"OK"`)
+
+	client = startTestClient(t, respond, WithSecret("s3cret"), WithLogger(logger), WithoutLogRedaction())
+	require.NoError(t, client.SaveSession())
+
+	assert.Contains(t, (<-frames).data, `"params":["token:s3cret"]`)
+	<-frames
+}
+
+func TestAddURIAtPositionWithoutOptions(t *testing.T) {
+	received := make(chan []interface{}, 1)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		received <- params
+		return "2089b05ecca3d829"
+	})
+
+	_, err := client.AddURIAtPosition(URIs("https://example.org/file"), 0, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{
+		[]interface{}{"https://example.org/file"},
+		map[string]interface{}{},
+		float64(0),
+	}, <-received)
+}
+
+func TestAddURIGID(t *testing.T) {
+	received := make(chan []interface{}, 1)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		received <- params
+		return "2089b05ecca3d829"
+	})
+
+	gid, err := client.AddURI(URIs("https://example.org/file"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2089b05ecca3d829", gid.GID, "gid assigned by aria2 should be returned")
+	<-received
+
+	gid, err = client.AddURI(URIs("https://example.org/file"), &Options{GID: "2089b05ecca3d829"})
+	require.NoError(t, err)
+	assert.Equal(t, "2089b05ecca3d829", gid.GID)
+	assert.Equal(t, map[string]interface{}{"gid": "2089b05ecca3d829"}, (<-received)[1])
+}
+
+func TestAddURIInvalidGID(t *testing.T) {
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		t.Errorf("unexpected call to %s", method)
+		return nil
+	})
+
+	for _, gid := range []string{"2089b05ecca3d8", "2089b05ecca3d82g", "0000000000000000"} {
+		_, err := client.AddURI(URIs("https://example.org/file"), &Options{GID: gid})
+		assert.Equal(t, ErrInvalidGID, err, gid)
+	}
+
+	_, err := client.AddTorrent([]byte("torrent"), nil, &Options{GID: "invalid"})
+	assert.Equal(t, ErrInvalidGID, err)
+
+	_, err = client.AddMetalink([]byte("metalink"), &Options{GID: "invalid"})
+	assert.Equal(t, ErrInvalidGID, err)
+}
+
+func TestAddURIDuplicate(t *testing.T) {
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		fault := map[string]interface{}{"code": 1, "message": "GID 2089b05ecca3d829 is not unique."}
+		if method == "system.multicall" {
+			return []interface{}{fault}
+		}
+
+		return &RPCError{Code: 1, Message: fault["message"].(string)}
+	})
+
+	_, err := client.AddURI(URIs("https://example.org/file"), &Options{GID: "2089b05ecca3d829"})
+	assert.Equal(t, ErrDuplicateDownload, err)
+
+	_, errs, err := client.AddURIs([]AddURIJob{{URIs: URIs("https://example.org/file")}})
+	require.NoError(t, err)
+	assert.Equal(t, []error{ErrDuplicateDownload}, errs)
+}
+
+func TestDownloadErrorDuplicate(t *testing.T) {
+	assert.True(t, errors.Is(&DownloadError{Code: SameFileBeingDownloaded}, ErrDuplicateDownload))
+	assert.True(t, errors.Is(&DownloadError{Code: SameInfoHashBeingDownloaded}, ErrDuplicateDownload))
+	assert.True(t, errors.Is(&DownloadError{Code: SameFileBeingDownloaded}, ErrDownloadError))
+	assert.False(t, errors.Is(&DownloadError{Code: NetworkError}, ErrDuplicateDownload))
+}
+
+func TestPing(t *testing.T) {
+	var unauthorized int32
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		assert.Equal(t, "aria2.getVersion", method)
+		if atomic.LoadInt32(&unauthorized) == 1 {
+			return &RPCError{Code: 1, Message: "Unauthorized"}
+		}
+
+		return map[string]interface{}{"version": "1.35.0"}
+	})
+
+	assert.NoError(t, client.Ping())
+
+	atomic.StoreInt32(&unauthorized, 1)
+	assert.Equal(t, &RPCError{Code: 1, Message: "Unauthorized"}, client.Ping())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Error(t, client.PingWithContext(ctx))
+}
+
+func TestAddURIStatus(t *testing.T) {
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		require.Equal(t, "system.multicall", method)
+
+		calls := params[0].([]interface{})
+		require.Len(t, calls, 2)
+
+		add := calls[0].(map[string]interface{})
+		tell := calls[1].(map[string]interface{})
+		assert.Equal(t, "aria2.addUri", add["methodName"])
+		assert.Equal(t, "aria2.tellStatus", tell["methodName"])
+
+		gid := add["params"].([]interface{})[1].(map[string]interface{})["gid"].(string)
+		assert.True(t, IsValidGID(gid), gid)
+		assert.Equal(t, []interface{}{gid}, tell["params"])
+
+		return []interface{}{
+			[]interface{}{gid},
+			[]interface{}{map[string]interface{}{"gid": gid, "status": "complete", "dir": "/downloads"}},
+		}
+	})
+
+	status, err := client.AddURIStatus(URIs("https://example.org/file"), nil)
+	require.NoError(t, err)
+	assert.True(t, IsValidGID(status.GID))
+	assert.Equal(t, StatusCompleted, status.Status)
+	assert.Equal(t, "/downloads", status.Dir)
+
+	options := &Options{GID: "2089b05ecca3d829"}
+	status, err = client.AddURIStatus(URIs("https://example.org/file"), options)
+	require.NoError(t, err)
+	assert.Equal(t, "2089b05ecca3d829", status.GID)
+
+	_, err = client.AddURIStatus(URIs("https://example.org/file"), &Options{GID: "invalid"})
+	assert.Equal(t, ErrInvalidGID, err)
+}
+
+func TestAddMagnetAndWaitMetadata(t *testing.T) {
+	const magnet = "magnet:?xt=urn:btih:c12fe1c06bba254a9dc9f519b335aa7c1367a88a"
+
+	followedBy := []string{"2089b05ecca3d82a"}
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		switch method {
+		case "aria2.addUri":
+			assert.Equal(t, []interface{}{magnet}, params[0])
+			return "2089b05ecca3d829"
+		case "aria2.tellStatus":
+			assert.Equal(t, "2089b05ecca3d829", params[0])
+			return map[string]interface{}{"gid": "2089b05ecca3d829", "status": "complete", "followedBy": followedBy}
+		}
+
+		return nil
+	})
+
+	gid, err := client.AddMagnetAndWaitMetadata(magnet, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2089b05ecca3d82a", gid.GID)
+
+	followedBy = nil
+	_, err = client.AddMagnetAndWaitMetadata(magnet, nil)
+	assert.Equal(t, ErrMetadataOnly, err)
+
+	_, err = client.AddMagnet("https://example.org/file.torrent", nil)
+	assert.Error(t, err)
+}
+
+func TestFollowedDownloads(t *testing.T) {
+	statuses := map[string]map[string]interface{}{
+		"1": {"gid": "1", "followedBy": []string{"2", "3"}},
+		"2": {"gid": "2", "belongsTo": "1"},
+		"3": {"gid": "3", "belongsTo": "1"},
+	}
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		status, ok := statuses[params[0].(string)]
+		if !ok {
+			return &RPCError{Code: 1, Message: "not found"}
+		}
+
+		return status
+	})
+
+	followed, err := client.FollowedDownloads("1")
+	require.NoError(t, err)
+	require.Len(t, followed, 2)
+	assert.Equal(t, "2", followed[0].GID)
+	assert.Equal(t, "3", followed[1].GID)
+
+	parent, err := client.ParentDownload("3")
+	require.NoError(t, err)
+	require.NotNil(t, parent)
+	assert.Equal(t, "1", parent.GID)
+
+	parent, err = client.ParentDownload("1")
+	assert.NoError(t, err)
+	assert.Nil(t, parent)
+
+	_, err = client.ParentDownload("4")
+	assert.Error(t, err)
+}
+
+func TestWaitForDownloadFunc(t *testing.T) {
+	responses := []string{"waiting", "active", "error"}
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		status := responses[0]
+		if len(responses) > 1 {
+			responses = responses[1:]
+		}
+
+		return map[string]interface{}{"gid": "2089b05ecca3d829", "status": status, "errorCode": "3"}
+	}, WithPollInterval(time.Millisecond))
+
+	var received []DownloadStatus
+	status, err := client.WaitForDownloadFunc("2089b05ecca3d829", func(status Status) {
+		received = append(received, status.Status)
+	})
+
+	assert.Equal(t, []DownloadStatus{StatusWaiting, StatusActive, StatusError}, received)
+	assert.Equal(t, StatusError, status.Status)
+	assert.True(t, errors.Is(err, ErrDownloadError))
+}
+
+func TestSetMaxConcurrentDownloads(t *testing.T) {
+	received := make(chan []interface{}, 1)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		received <- params
+		if params[0].(map[string]interface{})["max-concurrent-downloads"] == "100" {
+			return &RPCError{Code: 1, Message: "We encountered a problem while processing the option"}
+		}
+
+		return "OK"
+	})
+
+	require.NoError(t, client.SetMaxConcurrentDownloads(3))
+	assert.Equal(t, []interface{}{map[string]interface{}{"max-concurrent-downloads": "3"}}, <-received)
+
+	assert.Error(t, client.SetMaxConcurrentDownloads(0))
+
+	err := client.SetMaxConcurrentDownloads(100)
+	<-received
+
+	var optionErr *OptionError
+	require.True(t, errors.As(err, &optionErr))
+	assert.Equal(t, "max-concurrent-downloads", optionErr.Option)
+	assert.Equal(t, "100", optionErr.Value)
+
+	var rpcErr *RPCError
+	assert.True(t, errors.As(err, &rpcErr))
+}
+
+func TestStreamActive(t *testing.T) {
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		return []map[string]interface{}{
+			{"gid": "1", "status": "active", "totalLength": "100"},
+			{"gid": "2", "status": "active", "totalLength": ""},
+		}
+	})
+
+	statuses, errs := client.StreamActive()
+
+	var gids []string
+	for status := range statuses {
+		gids = append(gids, status.GID)
+	}
+
+	assert.Equal(t, []string{"1", "2"}, gids)
+	assert.NoError(t, <-errs)
+}
+
+func TestStreamActiveCancel(t *testing.T) {
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		return []map[string]interface{}{{"gid": "1"}, {"gid": "2"}}
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	statuses, errs := client.StreamActiveWithContext(ctx)
+
+	assert.Equal(t, "1", (<-statuses).GID)
+	cancel()
+
+	assert.Equal(t, context.Canceled, <-errs)
+	_, ok := <-statuses
+	assert.False(t, ok)
+}
+
+func TestCallWithID(t *testing.T) {
+	frames := make(chan []byte, 2)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		return &RPCError{Code: 1, Message: "failed"}
+	}, WithLogger(func(dir Direction, frame []byte) {
+		frames <- append([]byte(nil), frame...)
+	}))
+
+	id, err := client.CallWithID(context.Background(), "aria2.remove", []interface{}{"1"}, nil)
+	assert.Error(t, err)
+	assert.NotEmpty(t, id)
+
+	var sent struct {
+		ID string `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(<-frames, &sent))
+	assert.Equal(t, id, sent.ID)
+}
+
+func TestTellBitTorrentStatus(t *testing.T) {
+	received := make(chan []interface{}, 1)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		received <- params
+		return map[string]interface{}{
+			"infoHash":    "248d0a1cd08284299de78d5c1ed359bb46717d8c",
+			"numSeeders":  "3",
+			"seeder":      "false",
+			"connections": "7",
+			"bittorrent":  map[string]interface{}{"mode": "single", "info": map[string]string{"name": "file"}},
+		}
+	})
+
+	bt, summary, err := client.TellBitTorrentStatus("2089b05ecca3d829")
+	require.NoError(t, err)
+
+	assert.Equal(t, []interface{}{
+		"2089b05ecca3d829",
+		[]interface{}{"infoHash", "numSeeders", "seeder", "bittorrent", "connections"},
+	}, <-received)
+	assert.Equal(t, TorrentModeSingle, bt.Mode)
+	assert.Equal(t, "file", bt.Info.Name)
+	assert.Equal(t, InfoHashSummary{
+		InfoHash:    "248d0a1cd08284299de78d5c1ed359bb46717d8c",
+		NumSeeders:  3,
+		Connections: 7,
+	}, summary)
+}
+
+func TestAddURIs(t *testing.T) {
+	calls := make(chan []interface{}, 1)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		calls <- params
+		return []interface{}{
+			[]interface{}{"2089b05ecca3d829"},
+			map[string]interface{}{"code": 1, "message": "No URI to download."},
+		}
+	})
+
+	gids, errs, err := client.AddURIs([]AddURIJob{
+		{URIs: URIs("https://example.org/a"), Options: &Options{Dir: "/downloads"}},
+		{URIs: URIs()},
+	})
+	require.NoError(t, err)
+	require.Len(t, gids, 2)
+	require.Len(t, errs, 2)
+
+	assert.Equal(t, "2089b05ecca3d829", gids[0].GID)
+	assert.NoError(t, errs[0])
+	assert.Empty(t, gids[1].GID)
+	assert.Equal(t, &RPCError{Code: 1, Message: "No URI to download."}, errs[1])
+
+	assert.Equal(t, []interface{}{[]interface{}{
+		map[string]interface{}{"methodName": "aria2.addUri", "params": []interface{}{
+			[]interface{}{"https://example.org/a"},
+			map[string]interface{}{"dir": "/downloads"},
+		}},
+		map[string]interface{}{"methodName": "aria2.addUri", "params": []interface{}{[]interface{}{}}},
+	}}, <-calls)
+
+	gids, errs, err = client.AddURIs([]AddURIJob{
+		{URIs: URIs("https://example.org/a"), Options: &Options{Dir: "/downloads"}},
+		{URIs: URIs("https://example.org/b"), Options: &Options{GID: "not a gid"}},
+		{URIs: URIs()},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "2089b05ecca3d829", gids[0].GID)
+	assert.Equal(t, []error{nil, ErrInvalidGID, &RPCError{Code: 1, Message: "No URI to download."}}, errs)
+	assert.Len(t, (<-calls)[0], 2, "the job with the invalid GID shouldn't be sent")
+
+	_, errs, err = client.AddURIs([]AddURIJob{{URIs: URIs("https://example.org/b"), Options: &Options{GID: "0000000000000000"}}})
+	require.NoError(t, err)
+	assert.Equal(t, []error{ErrInvalidGID}, errs)
+	assert.Empty(t, calls)
+}
+
+func TestRemoveWhere(t *testing.T) {
+	calls := make(chan []interface{}, 1)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		switch method {
+		case "aria2.tellActive":
+			return []map[string]string{{"gid": "1", "status": "active"}}
+		case "aria2.tellWaiting":
+			return []map[string]string{{"gid": "2", "status": "paused"}, {"gid": "3", "status": "waiting"}}
+		case "aria2.tellStopped":
+			return []map[string]string{{"gid": "4", "status": "error"}, {"gid": "5", "status": "complete"}}
+		case "system.multicall":
+			calls <- params
+			return []interface{}{
+				[]interface{}{"2"},
+				[]interface{}{"OK"},
+				map[string]interface{}{"code": 1, "message": "Could not remove download result of GID#5"},
+			}
+		}
+
+		return nil
+	})
+
+	removed, err := client.RemoveWhere(func(status Status) bool {
+		return status.Status == StatusPaused || status.Status == StatusError || status.GID == "5"
+	})
+	assert.Equal(t, &RPCError{Code: 1, Message: "Could not remove download result of GID#5"}, err)
+	require.Len(t, removed, 2)
+	assert.Equal(t, "2", removed[0].GID)
+	assert.Equal(t, "4", removed[1].GID)
+
+	assert.Equal(t, []interface{}{[]interface{}{
+		map[string]interface{}{"methodName": "aria2.remove", "params": []interface{}{"2"}},
+		map[string]interface{}{"methodName": "aria2.removeDownloadResult", "params": []interface{}{"4"}},
+		map[string]interface{}{"methodName": "aria2.removeDownloadResult", "params": []interface{}{"5"}},
+	}}, <-calls)
+}
+
+func TestFindByGIDPrefix(t *testing.T) {
+	keys := make(chan interface{}, 3)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		if len(params) > 0 {
+			select {
+			case keys <- params[len(params)-1]:
+			default:
+			}
+		}
+
+		switch method {
+		case "aria2.tellActive":
+			return []map[string]string{{"gid": "aa00000000000001"}, {"gid": "bb00000000000001"}}
+		case "aria2.tellWaiting":
+			return []map[string]string{{"gid": "aa00000000000002"}}
+		case "aria2.tellStopped":
+			return []map[string]string{{"gid": "aa00000000000003"}, {"gid": "cc00000000000001"}}
+		}
+
+		return nil
+	})
+
+	statuses, err := client.FindByGIDPrefix("AA", "status")
+	require.NoError(t, err)
+	assert.Equal(t, []Status{{GID: "aa00000000000001"}, {GID: "aa00000000000002"}, {GID: "aa00000000000003"}}, statuses)
+
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, []interface{}{"gid", "status"}, <-keys)
+	}
+
+	statuses, err = client.FindByGIDPrefix("dd")
+	require.NoError(t, err)
+	assert.Empty(t, statuses)
+}
+
+func TestWithMaxMessageSize(t *testing.T) {
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		return strings.Repeat("a", len(params[0].(string)))
+	}, WithMaxMessageSize(128))
+
+	var reply string
+	require.NoError(t, client.Call("echo", []interface{}{"small"}, &reply))
+	assert.Equal(t, "aaaaa", reply)
+
+	err := client.Call("echo", []interface{}{strings.Repeat("b", 256)}, &reply)
+	assert.Equal(t, ErrMessageTooLarge, err)
+}
+
+func TestChangeOptionsMany(t *testing.T) {
+	calls := make(chan []interface{}, 1)
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		calls <- params
+		return []interface{}{
+			[]interface{}{"OK"},
+			map[string]interface{}{"code": 1, "message": "Cannot change option max-download-limit"},
+		}
+	})
+
+	changes := Options{}
+	changes.SetMaxDownloadLimit(1024)
+
+	errs, err := client.ChangeOptionsMany([]GID{client.GetGID("1"), client.GetGID("2")}, changes)
+	require.NoError(t, err)
+	assert.Equal(t, []error{nil, &RPCError{Code: 1, Message: "Cannot change option max-download-limit"}}, errs)
+
+	options := map[string]interface{}{"max-download-limit": "1024"}
+	assert.Equal(t, []interface{}{[]interface{}{
+		map[string]interface{}{"methodName": "aria2.changeOption", "params": []interface{}{"1", options}},
+		map[string]interface{}{"methodName": "aria2.changeOption", "params": []interface{}{"2", options}},
+	}}, <-calls)
+
+	errs, err = client.ChangeOptionsMany(nil, changes)
+	require.NoError(t, err)
+	assert.Nil(t, errs)
+}
+
+func TestMultiCallOrder(t *testing.T) {
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		calls := params[0].([]interface{})
+
+		results := make([]interface{}, len(calls))
+		for i, call := range calls {
+			gid := call.(map[string]interface{})["params"].([]interface{})[0].(string)
+			if gid == "2" {
+				results[i] = map[string]interface{}{"code": 1, "message": "GID 2 is not found"}
+			} else {
+				results[i] = []interface{}{map[string]string{"gid": gid}}
+			}
+		}
+
+		if len(calls) == 4 {
+			// drop a result to check the number of results is verified
+			results = results[:3]
+		}
+
+		return results
+	})
+
+	statuses, err := client.BatchTellStatus([]string{"1", "2", "3"})
+	assert.Equal(t, &RPCError{Code: 1, Message: "GID 2 is not found"}, err)
+	assert.Equal(t, []Status{{GID: "1"}, {}, {GID: "3"}}, statuses, "a fault should occupy its slot")
+
+	_, err = client.BatchTellStatus([]string{"1", "3", "4", "5"})
+	assert.EqualError(t, err, "aria2 returned 3 results for 4 calls")
+}
+
+func TestWithIDGenerator(t *testing.T) {
+	var counter int32
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		return "OK"
+	}, WithIDGenerator(func() string {
+		return fmt.Sprintf("trace-%d", atomic.AddInt32(&counter, 1))
+	}))
+
+	id, err := client.CallWithID(context.Background(), "aria2.saveSession", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "trace-1", id)
+
+	id, err = client.CallWithID(context.Background(), "aria2.saveSession", nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "trace-2", id)
+}
+
+func TestWithNetDialer(t *testing.T) {
+	server := httptest.NewServer(testHandler(func(method string, params []interface{}) interface{} {
+		return "OK"
+	}))
+	defer server.Close()
+
+	dialed := make(chan string, 1)
+	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
+		dialed <- addr
+		var dialer net.Dialer
+		return dialer.DialContext(ctx, network, server.Listener.Addr().String())
+	}
+
+	client, err := Dial("ws://aria2.invalid:6800/jsonrpc", "", WithNetDialer(dial))
+	require.NoError(t, err)
+	defer client.Close()
+
+	assert.Equal(t, "aria2.invalid:6800", <-dialed)
+	assert.NoError(t, client.SaveSession())
+}
@@ -0,0 +1,229 @@
+package arigo
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"time"
+)
+
+const (
+	// DefaultPollInterval is the default interval in which the status of a download
+	// is polled while waiting for it to finish.
+	DefaultPollInterval = 5 * time.Second
+
+	// DefaultErrorBufferSize is the default capacity of the channel returned by Client.Errors().
+	DefaultErrorBufferSize = 16
+)
+
+// ClientOption configures a Client.
+// ClientOptions can be passed to the Dial() and NewClient() functions.
+type ClientOption func(*Client)
+
+// WithPollInterval sets the interval in which the status of a download is polled
+// while waiting for it to finish.
+// Polling is only a fallback for when a notification from aria2 is missed.
+// An interval of 0 disables polling and relies on notifications alone.
+func WithPollInterval(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.pollInterval = interval
+	}
+}
+
+// WithRequestTimeout sets a default timeout for every call made by the client.
+// It only applies to calls whose context has no deadline,
+// a deadline set on the context of a call always wins over the default.
+// By default calls have no timeout.
+func WithRequestTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.requestTimeout = timeout
+	}
+}
+
+// WithKeepAlive enables sending WebSocket pings to detect dead connections early.
+// A ping is sent every interval and the connection is considered lost
+// if aria2 doesn't answer within timeout.
+// Lost connections are re-established if WithReconnect() is used.
+func WithKeepAlive(interval, timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.keepAliveInterval = interval
+		c.keepAliveTimeout = timeout
+	}
+}
+
+// WithTLSConfig sets the TLS configuration used by Dial() for "wss" urls.
+// This can be used to provide a custom CA or a client certificate.
+// If config.ServerName is empty, the host of the url is used for SNI.
+//
+// The option has no effect on clients created using NewClient().
+func WithTLSConfig(config *tls.Config) ClientOption {
+	return func(c *Client) {
+		c.tlsConfig = config
+	}
+}
+
+// WithNetDialer sets the function Dial() uses to establish the network connection to aria2.
+// This can be used to connect through a SOCKS5 proxy or to bind to a specific interface.
+// By default net.Dialer is used.
+// When connecting to a "wss" url, the TLS handshake is still performed by the client.
+//
+// The option has no effect on clients created using NewClient().
+func WithNetDialer(dial func(ctx context.Context, network, addr string) (net.Conn, error)) ClientOption {
+	return func(c *Client) {
+		c.netDial = dial
+	}
+}
+
+// WithMaxMessageSize limits the size of the messages received from aria2 to size bytes.
+// By default the size isn't limited.
+// If aria2 sends a larger message, for example the peer list of a big torrent,
+// the connection is closed and the calls which are in-flight return ErrMessageTooLarge.
+//
+// The option has no effect on clients created using NewClient().
+func WithMaxMessageSize(size int64) ClientOption {
+	return func(c *Client) {
+		c.maxMessageSize = size
+	}
+}
+
+// WithHeader adds a header to the WebSocket handshake performed by Dial().
+// This can be used to authenticate with a reverse proxy in front of aria2,
+// for example using an "Authorization" header.
+// Calling it multiple times with the same key adds multiple values.
+//
+// The option has no effect on clients created using NewClient().
+func WithHeader(key, value string) ClientOption {
+	return func(c *Client) {
+		c.header.Add(key, value)
+	}
+}
+
+// WithOrigin sets the "Origin" header of the WebSocket handshake performed by Dial().
+//
+// The option has no effect on clients created using NewClient().
+func WithOrigin(origin string) ClientOption {
+	return func(c *Client) {
+		c.header.Set("Origin", origin)
+	}
+}
+
+// WithErrorBuffer sets the capacity of the channel returned by Client.Errors().
+// Errors which occur while the channel is full are discarded.
+func WithErrorBuffer(size int) ClientOption {
+	return func(c *Client) {
+		c.errBufferSize = size
+	}
+}
+
+// WithLogger sets a logger which is called with every frame sent to or received from aria2.
+// This is meant for debugging protocol issues.
+// The secret token is redacted in the logged frames unless WithoutLogRedaction() is used.
+//
+// Frames are only logged for WebSocket connections, not for clients created using NewClientWithConn().
+func WithLogger(logger FrameLogger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithoutLogRedaction disables redacting the secret token in the frames passed to the logger
+// set using WithLogger().
+func WithoutLogRedaction() ClientOption {
+	return func(c *Client) {
+		c.logSecret = true
+	}
+}
+
+// WithIDGenerator sets the function which generates the JSON-RPC ids of the requests sent to aria2.
+// By default the ids are consecutive numbers.
+// Custom ids can be used to correlate calls across systems, for example by embedding a trace id.
+//
+// Responses are matched to requests by their id, so generate must return unique ids
+// for the lifetime of a connection. A call whose id is still in use by another call fails.
+// generate is never called concurrently.
+//
+// The option has no effect on clients created using NewClientWithConn().
+func WithIDGenerator(generate func() string) ClientOption {
+	return func(c *Client) {
+		c.idGenerator = generate
+	}
+}
+
+// WithAutoPurge makes the client remove the download results of downloads
+// which stopped more than maxAge ago, so they don't accumulate in long-running aria2 instances.
+// The age is measured from the stop, completion or error event received by the client.
+// Results of downloads which stopped while the client wasn't connected are kept, use
+// PurgeDownloadResults() to remove them.
+func WithAutoPurge(maxAge time.Duration) ClientOption {
+	return func(c *Client) {
+		c.autoPurgeAge = maxAge
+	}
+}
+
+// WithCounts makes the client keep track of the number of active, waiting and stopped downloads,
+// see Client.Counts().
+// The counts are reconciled with aria2 every interval and after reconnecting.
+func WithCounts(interval time.Duration) ClientOption {
+	return func(c *Client) {
+		c.countsInterval = interval
+	}
+}
+
+// WithMetrics sets the Metrics which receive the measurements taken by the client.
+// By default measurements are discarded.
+func WithMetrics(metrics Metrics) ClientOption {
+	return func(c *Client) {
+		c.metrics = metrics
+	}
+}
+
+// WithReconnect enables automatic reconnection using the given policy.
+// When the connection to aria2 is lost, calls which are in-flight return ErrConnectionLost
+// and the client tries to re-establish the connection.
+// Event listeners stay subscribed across reconnects.
+//
+// Reconnecting is only possible for clients created using the Dial() function.
+func WithReconnect(policy ReconnectPolicy) ClientOption {
+	return func(c *Client) {
+		c.reconnectPolicy = &policy
+	}
+}
+
+// WithReconnectListener registers a listener which is called for every
+// attempt to re-establish the connection.
+func WithReconnectListener(listener ReconnectListener) ClientOption {
+	return func(c *Client) {
+		c.reconnectListeners = append(c.reconnectListeners, listener)
+	}
+}
+
+// WithResumeReconcile makes the client fetch the state of the downloads after a lost connection
+// was re-established.
+// The active downloads and up to stopped of the most recently stopped downloads are
+// delivered in the ResumeEvent on the Resumed channel of every Subscription.
+func WithResumeReconcile(stopped uint) ClientOption {
+	return func(c *Client) {
+		c.resumeReconcile = true
+		c.resumeStopped = stopped
+	}
+}
+
+// WithSecret sets the secret token which is set on the aria2 server using --rpc-secret.
+// It overrides the authToken passed to Dial() or NewClient().
+// The token is prepended to the parameters of every call, including the calls
+// made using MultiCall().
+func WithSecret(token string) ClientOption {
+	return func(c *Client) {
+		c.authToken = token
+	}
+}
+
+// WithRetry enables retrying calls which failed because of a transient transport error,
+// for example because the connection was lost.
+// See RetryPolicy for the methods which are retried.
+// Retries stop once the context of the call is done.
+func WithRetry(policy RetryPolicy) ClientOption {
+	return func(c *Client) {
+		c.retryPolicy = &policy
+	}
+}
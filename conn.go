@@ -0,0 +1,84 @@
+package arigo
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jae-jae/arigo/internal/pkg/wsrpc"
+)
+
+// NotificationHandler is called for every notification sent by aria2.
+// params contains the raw JSON parameters of the notification.
+type NotificationHandler func(method string, params json.RawMessage)
+
+// Direction tells whether a frame passed to a FrameLogger was sent to or received from aria2.
+type Direction = wsrpc.Direction
+
+const (
+	// DirectionSent is the Direction of frames sent to aria2.
+	DirectionSent = wsrpc.Sent
+	// DirectionReceived is the Direction of frames received from aria2.
+	DirectionReceived = wsrpc.Received
+)
+
+// FrameLogger is called with every raw JSON frame sent to or received from aria2.
+// It must not modify or retain the frame.
+type FrameLogger func(dir Direction, frame []byte)
+
+// ConnState is the state of the connection of a Client to aria2.
+type ConnState int
+
+const (
+	// StateConnecting is the state of a client which is establishing its first connection.
+	StateConnecting ConnState = iota
+	// StateConnected is the state of a client which is connected to aria2.
+	StateConnected
+	// StateReconnecting is the state of a client which lost its connection
+	// and tries to re-establish it, see WithReconnect().
+	StateReconnecting
+	// StateClosed is the state of a client whose connection was closed
+	// or lost without being re-established. Clients don't leave this state.
+	StateClosed
+)
+
+func (s ConnState) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateConnected:
+		return "connected"
+	case StateReconnecting:
+		return "reconnecting"
+	case StateClosed:
+		return "closed"
+	default:
+		return "unknown"
+	}
+}
+
+// RPCConn is the transport a Client uses to communicate with aria2.
+// The Client created by Dial() or NewClient() uses a WebSocket connection.
+// Other implementations, such as the fake in the arigotest package,
+// can be passed to NewClientWithConn().
+type RPCConn interface {
+	// Call calls the method with the given params and unmarshals the result into reply.
+	// reply may be nil, in which case the result is discarded.
+	// Errors reported by aria2 should be returned as an *RPCError.
+	Call(ctx context.Context, method string, params []interface{}, reply interface{}) error
+
+	// Run passes all notifications to handler until the connection is closed.
+	// It returns the error which caused the connection to stop.
+	Run(handler NotificationHandler) error
+
+	// Close closes the connection.
+	// Calls which are in-flight should return ErrClientClosed.
+	Close() error
+}
+
+// wsConn is the RPCConn using a WebSocket connection.
+type wsConn struct {
+	*wsrpc.Client
+}
+
+func (c wsConn) Run(handler NotificationHandler) error {
+	return c.Client.Run(wsrpc.NotificationHandler(handler))
+}
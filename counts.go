@@ -0,0 +1,176 @@
+package arigo
+
+import (
+	"sync"
+	"time"
+)
+
+// Counts holds the number of downloads in each state, see Client.Counts().
+type Counts struct {
+	Active  uint // The number of active downloads
+	Waiting uint // The number of waiting downloads, including paused ones
+
+	// The number of stopped downloads in the current session.
+	// Like Stats.NumStopped, it is capped by the MaxDownloadResult option when reconciling.
+	Stopped uint
+}
+
+// countState is the state of a download as far as counting is concerned.
+type countState int
+
+const (
+	countUnknown countState = iota
+	countActive
+	countWaiting
+	countStopped
+)
+
+// countTracker keeps the Counts up to date using download events
+// and reconciles them with the global stats of aria2.
+type countTracker struct {
+	mut    sync.Mutex
+	counts Counts
+	// states holds the state of the downloads which were reported active or waiting
+	// by an event since the last reconcile.
+	states map[string]countState
+}
+
+func newCountTracker() *countTracker {
+	return &countTracker{states: make(map[string]countState)}
+}
+
+// get returns the current counts.
+func (t *countTracker) get() Counts {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	return t.counts
+}
+
+// counter returns a pointer to the count of the given state.
+func (t *countTracker) counter(state countState) *uint {
+	switch state {
+	case countActive:
+		return &t.counts.Active
+	case countWaiting:
+		return &t.counts.Waiting
+	default:
+		return &t.counts.Stopped
+	}
+}
+
+// move records that the download with the given gid changed to state.
+// The previous state is only known if an event was received for the download
+// since the last reconcile, otherwise the download isn't subtracted from any count.
+func (t *countTracker) move(gid string, state countState) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	previous := t.states[gid]
+	if previous == state {
+		return
+	}
+
+	if previous != countUnknown {
+		if count := t.counter(previous); *count > 0 {
+			*count--
+		}
+	}
+
+	*t.counter(state)++
+
+	// stopped downloads remain stopped, so they don't need to be remembered
+	if state == countStopped {
+		delete(t.states, gid)
+	} else {
+		t.states[gid] = state
+	}
+}
+
+// reconcile replaces the counts by the ones reported by aria2 and forgets the known states.
+func (t *countTracker) reconcile(stats Stats) {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	t.counts = Counts{Active: stats.NumActive, Waiting: stats.NumWaiting, Stopped: stats.NumStopped}
+	t.states = make(map[string]countState)
+}
+
+// countEvents maps the events which change the state of a download to the new state.
+var countEvents = map[EventType]countState{
+	StartEvent:    countActive,
+	PauseEvent:    countWaiting,
+	StopEvent:     countStopped,
+	CompleteEvent: countStopped,
+	ErrorEvent:    countStopped,
+}
+
+// startCounts starts tracking the download events to keep the counts returned by Counts() up to date.
+func (c *Client) startCounts() {
+	c.counts = newCountTracker()
+	c.countsResumed = make(chan struct{}, 1)
+
+	// events sent while the client was disconnected are lost, so the counts are reconciled after reconnecting.
+	c.resumeTarget.subscribe(func(*ResumeEvent) {
+		select {
+		case c.countsResumed <- struct{}{}:
+		default:
+		}
+	})
+
+	for evtType, state := range countEvents {
+		state := state
+		c.Subscribe(evtType, func(event *DownloadEvent) {
+			c.counts.move(event.GID, state)
+		})
+	}
+}
+
+// reconcileCounts reconciles the counts with aria2 right away, after every reconnect
+// and in the interval set using WithCounts() until the client is closed.
+func (c *Client) reconcileCounts() {
+	ticker := time.NewTicker(c.countsInterval)
+	defer ticker.Stop()
+
+	for {
+		// failed calls are ignored, the counts are reconciled again on the next tick.
+		_ = c.syncCounts()
+
+		select {
+		case <-c.closing:
+			return
+		case <-c.countsResumed:
+		case <-ticker.C:
+		}
+	}
+}
+
+// syncCounts reconciles the counts with the global stats of aria2.
+func (c *Client) syncCounts() error {
+	stats, err := c.GetGlobalStats()
+	if err != nil {
+		return err
+	}
+
+	c.counts.reconcile(stats)
+	return nil
+}
+
+// Counts returns the number of active, waiting and stopped downloads without making a call.
+// The counts are updated using the download events sent by aria2 and reconciled
+// with the global stats in the interval set using WithCounts() and after reconnecting.
+//
+// Between reconciles the counts are an estimate which may drift:
+// aria2 doesn't send an event when a download is added, events are delivered concurrently
+// so two events of the same download may be applied out of order,
+// and downloads whose previous state isn't known are only added to their new count.
+// Only the counts right after a reconcile are exact.
+//
+// Counts returns zero counts unless the WithCounts() option is used.
+func (c *Client) Counts() Counts {
+	if c.counts == nil {
+		return Counts{}
+	}
+
+	return c.counts.get()
+}
@@ -0,0 +1,121 @@
+package arigo
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/jae-jae/arigo/pkg/aria2proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// statsResponse returns the global stats with the given numbers of active, waiting and stopped downloads.
+func statsResponse(active, waiting, stopped int) map[string]string {
+	return map[string]string{
+		"numActive":  strconv.Itoa(active),
+		"numWaiting": strconv.Itoa(waiting),
+		"numStopped": strconv.Itoa(stopped),
+	}
+}
+
+// waitForCounts waits until the counts of client equal expected.
+func waitForCounts(t *testing.T, client *Client, expected Counts) {
+	deadline := time.Now().Add(time.Second)
+	for client.Counts() != expected {
+		if time.Now().After(deadline) {
+			t.Fatalf("counts weren't reconciled, got %+v", client.Counts())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestWithCounts(t *testing.T) {
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		return statsResponse(1, 2, 0)
+	}, WithCounts(time.Hour))
+
+	waitForCounts(t, client, Counts{Active: 1, Waiting: 2})
+
+	// the previous state of a isn't known, so it isn't subtracted from Waiting
+	client.handleNotification(aria2proto.OnDownloadStart, []byte(`[{"gid": "a"}]`))
+	assert.Equal(t, Counts{Active: 2, Waiting: 2}, client.Counts())
+
+	client.handleNotification(aria2proto.OnDownloadPause, []byte(`[{"gid": "a"}]`))
+	assert.Equal(t, Counts{Active: 1, Waiting: 3}, client.Counts())
+
+	client.handleNotification(aria2proto.OnDownloadStart, []byte(`[{"gid": "a"}]`))
+	assert.Equal(t, Counts{Active: 2, Waiting: 2}, client.Counts())
+
+	client.handleNotification(aria2proto.OnDownloadComplete, []byte(`[{"gid": "a"}]`))
+	assert.Equal(t, Counts{Active: 1, Waiting: 2, Stopped: 1}, client.Counts())
+
+	require.NoError(t, client.syncCounts())
+	assert.Equal(t, Counts{Active: 1, Waiting: 2}, client.Counts())
+	assert.Empty(t, client.counts.states)
+}
+
+func TestCountsRemovedWhileDisconnected(t *testing.T) {
+	var connections int32
+	drop := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		// a download is removed while the first connection is lost
+		active := 1
+		first := atomic.AddInt32(&connections, 1) == 1
+		if first {
+			active = 2
+		}
+
+		for {
+			var req map[string]interface{}
+			if first {
+				select {
+				case <-drop:
+					return
+				default:
+				}
+			}
+
+			if err := ws.ReadJSON(&req); err != nil {
+				return
+			}
+
+			result := statsResponse(active, 0, 0)
+			_ = ws.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": result})
+		}
+	}))
+	defer server.Close()
+
+	client, err := Dial("ws"+strings.TrimPrefix(server.URL, "http"), "",
+		WithReconnect(ReconnectPolicy{InitialDelay: 10 * time.Millisecond, MaxAttempts: 3}),
+		WithCounts(time.Hour),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	waitForCounts(t, client, Counts{Active: 2})
+
+	close(drop)
+	// makes the first connection notice the drop
+	go func() { _, _ = client.GetVersion() }()
+
+	waitForCounts(t, client, Counts{Active: 1})
+}
+
+func TestCountsDisabled(t *testing.T) {
+	client := newClient("", nil)
+	client.handleNotification(aria2proto.OnDownloadStart, []byte(`[{"gid": "a"}]`))
+	assert.Equal(t, Counts{}, client.Counts())
+}
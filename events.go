@@ -24,9 +24,14 @@ const (
 )
 
 // DownloadEvent represents the event emitted by aria2 concerning downloads.
-// It only contains the gid of the download.
+// It contains the gid of the download.
 type DownloadEvent struct {
-	GID string
+	GID string `json:"gid"`
+
+	// Status of the download when the event was delivered.
+	// It's only set for the ErrorEvents of a Subscription using SubscriptionOptions.ErrorStatus.
+	// It's nil if the status couldn't be retrieved, for example because the download result was already removed.
+	Status *Status `json:"-"`
 }
 
 func (e *DownloadEvent) String() string {
@@ -39,6 +44,7 @@ type EventListener func(event *DownloadEvent)
 
 // UnsubscribeFunc is a function which when called, unsubscribes from an
 // event.
+// A dispatch which was already in progress may still call the listener after unsubscribing.
 type UnsubscribeFunc func() bool
 
 // EventSubscriber is an interface which can be subscribed to.
@@ -113,19 +119,21 @@ func (t *eventTarget) Subscribe(evtType EventType, listener EventListener) Unsub
 }
 
 func (t *eventTarget) Dispatch(evtType EventType, event *DownloadEvent) {
+	// the listeners are called without holding the lock so that a slow listener
+	// doesn't hold up subscribing, unsubscribing and the dispatch of other events.
+	// unsubscribe modifies the slice in place, so it has to be copied.
 	t.mut.RLock()
-	defer t.mut.RUnlock()
+	listeners := append([]listenerData(nil), t.listenerMap[evtType]...)
+	t.mut.RUnlock()
 
 	var wg sync.WaitGroup
 
-	listeners := t.listenerMap[evtType]
-
 	wg.Add(len(listeners))
 	for _, listener := range listeners {
-		go func() {
+		go func(listener listenerData) {
 			listener.f(event)
 			wg.Done()
-		}()
+		}(listener)
 	}
 
 	wg.Wait()
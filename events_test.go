@@ -4,6 +4,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestEventTarget(t *testing.T) {
@@ -15,14 +16,14 @@ func TestEventTarget(t *testing.T) {
 		events = append(events, event)
 	})
 
-	evtTarget.Dispatch(StartEvent, &DownloadEvent{"1"})
-	evtTarget.Dispatch(CompleteEvent, &DownloadEvent{"2"})
-	evtTarget.Dispatch(StartEvent, &DownloadEvent{"3"})
+	evtTarget.Dispatch(StartEvent, &DownloadEvent{GID: "1"})
+	evtTarget.Dispatch(CompleteEvent, &DownloadEvent{GID: "2"})
+	evtTarget.Dispatch(StartEvent, &DownloadEvent{GID: "3"})
 
 	unsub()
 	unsub()
 
-	evtTarget.Dispatch(StartEvent, &DownloadEvent{"4"})
+	evtTarget.Dispatch(StartEvent, &DownloadEvent{GID: "4"})
 
 	require.Len(t, events, 2, "should only receive two events")
 
@@ -32,3 +33,39 @@ func TestEventTarget(t *testing.T) {
 	second := events[1].GID
 	assert.Equal(t, "3", second)
 }
+
+func TestEventTargetBlockingListener(t *testing.T) {
+	var target eventTarget
+
+	release := make(chan struct{})
+	called := make(chan struct{})
+	target.Subscribe(StartEvent, func(event *DownloadEvent) {
+		close(called)
+		<-release
+	})
+
+	dispatched := make(chan struct{})
+	go func() {
+		target.Dispatch(StartEvent, &DownloadEvent{GID: "1"})
+		close(dispatched)
+	}()
+	<-called
+
+	// subscribing, unsubscribing and dispatching other events must not wait for the blocked listener
+	done := make(chan struct{})
+	go func() {
+		unsubscribe := target.Subscribe(StopEvent, func(event *DownloadEvent) {})
+		target.Dispatch(StopEvent, &DownloadEvent{GID: "2"})
+		unsubscribe()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("blocked listener held up the event target")
+	}
+
+	close(release)
+	<-dispatched
+}
@@ -1,5 +1,7 @@
 package arigo
 
+import "strconv"
+
 //go:generate stringer -type=ExitStatus
 
 // ExitStatus is an integer returned by aria2 for downloads which describes why a download exited.
@@ -54,7 +56,7 @@ const (
 	// FileAlreadyExists indicates that the file already existed. See --allow-overwrite option.
 	FileAlreadyExists
 
-	//RenamingFailed indicates that renaming the file failed. See --auto-file-renaming option.
+	// RenamingFailed indicates that renaming the file failed. See --auto-file-renaming option.
 	RenamingFailed
 
 	// CouldNotOpenExistingFile indicates that aria2 could not open existing file.
@@ -96,6 +98,9 @@ const (
 	// MagnetURIBad indicates that the magnet URI was bad.
 	MagnetURIBad
 
+	// BadOption indicates that a bad/unrecognized option was given or an unexpected option argument was given.
+	BadOption
+
 	// RemoteServerHandleRequestError indicates that the remote server was unable to handle the request due to a
 	// temporary overloading or maintenance.
 	RemoteServerHandleRequestError
@@ -110,3 +115,59 @@ const (
 	// ChecksumValidationFailed indicates that the checksum validation failed.
 	ChecksumValidationFailed
 )
+
+var exitStatusDescriptions = [...]string{
+	Success:                        "all downloads were successful",
+	UnknownError:                   "an unknown error occurred",
+	Timeout:                        "a timeout occurred",
+	ResourceNotFound:               "a resource was not found",
+	ResourceNotFoundReached:        "the maximum number of \"resource not found\" errors was reached",
+	DownloadSpeedTooSlow:           "the download speed was too slow",
+	NetworkError:                   "a network problem occurred",
+	UnfinishedDownloads:            "there were unfinished downloads",
+	RemoteNoResume:                 "the remote server did not support resume",
+	NotEnoughDiskSpace:             "there was not enough disk space available",
+	PieceLengthMismatch:            "the piece length was different from the one in the .aria2 control file",
+	SameFileBeingDownloaded:        "the same file was being downloaded",
+	SameInfoHashBeingDownloaded:    "the same info hash torrent was being downloaded",
+	FileAlreadyExists:              "the file already existed",
+	RenamingFailed:                 "renaming the file failed",
+	CouldNotOpenExistingFile:       "could not open the existing file",
+	CouldNotCreateNewFile:          "could not create a new file or truncate the existing file",
+	FileIOError:                    "a file I/O error occurred",
+	CouldNotCreateDirectory:        "could not create the directory",
+	NameResolutionFailed:           "the name resolution failed",
+	MetalinkParsingFailed:          "could not parse the Metalink document",
+	FTPCommandFailed:               "an FTP command failed",
+	HTTPResponseHeaderBad:          "the HTTP response header was bad or unexpected",
+	TooManyRedirects:               "too many redirects occurred",
+	HTTPAuthorizationFailed:        "the HTTP authorization failed",
+	BencodedFileParseError:         "could not parse the bencoded file",
+	TorrentFileCorrupt:             "the .torrent file was corrupted or missing information",
+	MagnetURIBad:                   "the magnet URI was bad",
+	BadOption:                      "a bad or unrecognized option or option argument was given",
+	RemoteServerHandleRequestError: "the remote server was unable to handle the request",
+	JSONRPCParseError:              "could not parse the JSON-RPC request",
+	Reserved:                       "reserved",
+	ChecksumValidationFailed:       "the checksum validation failed",
+}
+
+// Description returns a human readable description of the exit status.
+func (i ExitStatus) Description() string {
+	if int(i) >= len(exitStatusDescriptions) {
+		return "unknown exit status " + strconv.Itoa(int(i))
+	}
+
+	return exitStatusDescriptions[i]
+}
+
+// IsRetryable reports whether the exit status indicates a transient error.
+// A download which failed with a transient error may succeed when it is retried.
+func (i ExitStatus) IsRetryable() bool {
+	switch i {
+	case Timeout, DownloadSpeedTooSlow, NetworkError, NameResolutionFailed, RemoteServerHandleRequestError:
+		return true
+	default:
+		return false
+	}
+}
@@ -36,15 +36,16 @@ func _() {
 	_ = x[BencodedFileParseError-25]
 	_ = x[TorrentFileCorrupt-26]
 	_ = x[MagnetURIBad-27]
-	_ = x[RemoteServerHandleRequestError-28]
-	_ = x[JSONRPCParseError-29]
-	_ = x[Reserved-30]
-	_ = x[ChecksumValidationFailed-31]
+	_ = x[BadOption-28]
+	_ = x[RemoteServerHandleRequestError-29]
+	_ = x[JSONRPCParseError-30]
+	_ = x[Reserved-31]
+	_ = x[ChecksumValidationFailed-32]
 }
 
-const _ExitStatus_name = "SuccessUnknownErrorTimeoutResourceNotFoundResourceNotFoundReachedDownloadSpeedTooSlowNetworkErrorUnfinishedDownloadsRemoteNoResumeNotEnoughDiskSpacePieceLengthMismatchSameFileBeingDownloadedSameInfoHashBeingDownloadedFileAlreadyExistsRenamingFailedCouldNotOpenExistingFileCouldNotCreateNewFileFileIOErrorCouldNotCreateDirectoryNameResolutionFailedMetalinkParsingFailedFTPCommandFailedHTTPResponseHeaderBadTooManyRedirectsHTTPAuthorizationFailedBencodedFileParseErrorTorrentFileCorruptMagnetURIBadRemoteServerHandleRequestErrorJSONRPCParseErrorReservedChecksumValidationFailed"
+const _ExitStatus_name = "SuccessUnknownErrorTimeoutResourceNotFoundResourceNotFoundReachedDownloadSpeedTooSlowNetworkErrorUnfinishedDownloadsRemoteNoResumeNotEnoughDiskSpacePieceLengthMismatchSameFileBeingDownloadedSameInfoHashBeingDownloadedFileAlreadyExistsRenamingFailedCouldNotOpenExistingFileCouldNotCreateNewFileFileIOErrorCouldNotCreateDirectoryNameResolutionFailedMetalinkParsingFailedFTPCommandFailedHTTPResponseHeaderBadTooManyRedirectsHTTPAuthorizationFailedBencodedFileParseErrorTorrentFileCorruptMagnetURIBadBadOptionRemoteServerHandleRequestErrorJSONRPCParseErrorReservedChecksumValidationFailed"
 
-var _ExitStatus_index = [...]uint16{0, 7, 19, 26, 42, 65, 85, 97, 116, 130, 148, 167, 190, 217, 234, 248, 272, 293, 304, 327, 347, 368, 384, 405, 421, 444, 466, 484, 496, 526, 543, 551, 575}
+var _ExitStatus_index = [...]uint16{0, 7, 19, 26, 42, 65, 85, 97, 116, 130, 148, 167, 190, 217, 234, 248, 272, 293, 304, 327, 347, 368, 384, 405, 421, 444, 466, 484, 496, 505, 535, 552, 560, 584}
 
 func (i ExitStatus) String() string {
 	if i >= ExitStatus(len(_ExitStatus_index)-1) {
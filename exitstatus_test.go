@@ -0,0 +1,34 @@
+package arigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExitStatusCodes(t *testing.T) {
+	assert.Equal(t, ExitStatus(0), Success)
+	assert.Equal(t, ExitStatus(3), ResourceNotFound)
+	assert.Equal(t, ExitStatus(9), NotEnoughDiskSpace)
+	assert.Equal(t, ExitStatus(24), HTTPAuthorizationFailed)
+	assert.Equal(t, ExitStatus(28), BadOption)
+	assert.Equal(t, ExitStatus(29), RemoteServerHandleRequestError)
+	assert.Equal(t, ExitStatus(32), ChecksumValidationFailed)
+}
+
+func TestExitStatusDescription(t *testing.T) {
+	assert.Equal(t, "NotEnoughDiskSpace", NotEnoughDiskSpace.String())
+	assert.Equal(t, "there was not enough disk space available", NotEnoughDiskSpace.Description())
+	assert.Equal(t, "unknown exit status 100", ExitStatus(100).Description())
+
+	for status := Success; status <= ChecksumValidationFailed; status++ {
+		assert.NotEmpty(t, status.Description(), status.String())
+	}
+}
+
+func TestExitStatusIsRetryable(t *testing.T) {
+	assert.True(t, Timeout.IsRetryable())
+	assert.True(t, NetworkError.IsRetryable())
+	assert.False(t, Success.IsRetryable())
+	assert.False(t, NotEnoughDiskSpace.IsRetryable())
+	assert.False(t, HTTPAuthorizationFailed.IsRetryable())
+}
@@ -1,5 +1,7 @@
 package arigo
 
+import "reflect"
+
 // File represents a single file downloaded by aria2.
 // It is returned by the GetFiles() method.
 type File struct {
@@ -20,3 +22,11 @@ type File struct {
 	Selected bool  `json:"selected,string"`
 	URIs     []URI `json:"uris"` // Array of URIs for this file.
 }
+
+var fileDecoder = newLenientDecoder(reflect.TypeOf(File{}))
+
+// UnmarshalJSON decodes the file sent by aria2.
+// Numeric fields which aria2 sent as an empty string are left at zero.
+func (f *File) UnmarshalJSON(data []byte) error {
+	return fileDecoder.decode(data, reflect.ValueOf(f).Elem())
+}
@@ -1,5 +1,11 @@
 package arigo
 
+import (
+	"context"
+	"encoding/hex"
+	"time"
+)
+
 // GID provides an object oriented approach to arigo.
 // Instead of calling the methods on the client directly,
 // you can call them on the GID instance.
@@ -12,6 +18,23 @@ func (gid *GID) String() string {
 	return gid.GID
 }
 
+// IsValid reports whether the gid has the format used by aria2.
+// See IsValidGID() for details.
+func (gid *GID) IsValid() bool {
+	return IsValidGID(gid.GID)
+}
+
+// IsValidGID reports whether gid has the format used by aria2,
+// which is a hex string of 16 characters.
+func IsValidGID(gid string) bool {
+	if len(gid) != 16 {
+		return false
+	}
+
+	_, err := hex.DecodeString(gid)
+	return err == nil
+}
+
 // Subscribe subscribes to the given event but only dispatches events concerning
 // this GID.
 func (gid *GID) Subscribe(evtType EventType, listener EventListener) UnsubscribeFunc {
@@ -22,9 +45,41 @@ func (gid *GID) Subscribe(evtType EventType, listener EventListener) Unsubscribe
 	})
 }
 
+// NewSubscription creates a Subscription which only delivers events concerning this GID.
+// See Client.NewSubscription() for details.
+func (gid *GID) NewSubscription(bufferSize int) *Subscription {
+	return gid.NewSubscriptionWithOptions(SubscriptionOptions{BufferSize: bufferSize})
+}
+
+// NewSubscriptionWithOptions is like NewSubscription() but the subscription is configured
+// using the given options.
+func (gid *GID) NewSubscriptionWithOptions(options SubscriptionOptions) *Subscription {
+	return newSubscription(context.Background(), gid, gid.client, gid.GID, options)
+}
+
+// NewSubscriptionWithContext is like NewSubscriptionWithOptions() but the subscription
+// is closed automatically once ctx is done.
+// See Client.NewSubscriptionWithContext() for details.
+func (gid *GID) NewSubscriptionWithContext(ctx context.Context, options SubscriptionOptions) (*Subscription, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if gid.client.isClosed() {
+		return nil, ErrClientClosed
+	}
+
+	return newSubscription(ctx, gid, gid.client, gid.GID, options), nil
+}
+
 // Delete removes the download from disk as well as from aria2.
 func (gid *GID) Delete() error {
-	return gid.client.Delete(gid.GID)
+	return gid.DeleteWithContext(context.Background())
+}
+
+// DeleteWithContext is like Delete() but the passed context can be used to cancel the call.
+func (gid *GID) DeleteWithContext(ctx context.Context) error {
+	return gid.client.DeleteWithContext(ctx, gid.GID)
 }
 
 // WaitForDownload waits for the download to finish.
@@ -32,11 +87,46 @@ func (gid *GID) WaitForDownload() error {
 	return gid.client.WaitForDownload(gid.GID)
 }
 
+// WaitForDownloadWithContext waits for the download to reach a terminal state
+// and returns its final status.
+// See Client.WaitForDownloadWithContext() for details.
+func (gid *GID) WaitForDownloadWithContext(ctx context.Context) (Status, error) {
+	return gid.client.WaitForDownloadWithContext(ctx, gid.GID)
+}
+
+// WaitForDownloadFunc waits for the download to reach a terminal state
+// and calls onUpdate with every status it retrieves while waiting.
+// See Client.WaitForDownloadFuncWithContext() for details.
+func (gid *GID) WaitForDownloadFunc(onUpdate func(status Status)) (Status, error) {
+	return gid.WaitForDownloadFuncWithContext(context.Background(), onUpdate)
+}
+
+// WaitForDownloadFuncWithContext is like WaitForDownloadFunc() but the passed context can be used to cancel the call.
+func (gid *GID) WaitForDownloadFuncWithContext(ctx context.Context, onUpdate func(status Status)) (Status, error) {
+	return gid.client.WaitForDownloadFuncWithContext(ctx, gid.GID, onUpdate)
+}
+
+// WatchStatus polls the status of the download in the given interval.
+// See Client.WatchStatusWithContext() for details.
+func (gid *GID) WatchStatus(interval time.Duration) (<-chan Status, <-chan error) {
+	return gid.client.WatchStatus(gid.GID, interval)
+}
+
+// WatchStatusWithContext is like WatchStatus() but the passed context can be used to stop polling.
+func (gid *GID) WatchStatusWithContext(ctx context.Context, interval time.Duration) (<-chan Status, <-chan error) {
+	return gid.client.WatchStatusWithContext(ctx, gid.GID, interval)
+}
+
 // Remove removes the download.
 // If the specified download is in progress, it is first stopped.
 // The status of the removed download becomes removed.
 func (gid *GID) Remove() error {
-	return gid.client.Remove(gid.GID)
+	return gid.RemoveWithContext(context.Background())
+}
+
+// RemoveWithContext is like Remove() but the passed context can be used to cancel the call.
+func (gid *GID) RemoveWithContext(ctx context.Context) error {
+	return gid.client.RemoveWithContext(ctx, gid.GID)
 }
 
 // ForceRemove removes the download.
@@ -44,7 +134,12 @@ func (gid *GID) Remove() error {
 // without performing any actions which take time, such as contacting BitTorrent trackers to
 // unregister the download first.
 func (gid *GID) ForceRemove() error {
-	return gid.client.ForceRemove(gid.GID)
+	return gid.ForceRemoveWithContext(context.Background())
+}
+
+// ForceRemoveWithContext is like ForceRemove() but the passed context can be used to cancel the call.
+func (gid *GID) ForceRemoveWithContext(ctx context.Context) error {
+	return gid.client.ForceRemoveWithContext(ctx, gid.GID)
 }
 
 // Pause pauses the download.
@@ -52,7 +147,12 @@ func (gid *GID) ForceRemove() error {
 // the download is placed in the front of the queue. While the status is paused,
 // the download is not started. To change status to waiting, use the Unpause() method.
 func (gid *GID) Pause() error {
-	return gid.client.Pause(gid.GID)
+	return gid.PauseWithContext(context.Background())
+}
+
+// PauseWithContext is like Pause() but the passed context can be used to cancel the call.
+func (gid *GID) PauseWithContext(ctx context.Context) error {
+	return gid.client.PauseWithContext(ctx, gid.GID)
 }
 
 // ForcePause pauses the download.
@@ -60,13 +160,23 @@ func (gid *GID) Pause() error {
 // without performing any actions which take time, such as contacting BitTorrent trackers to
 // unregister the download first.
 func (gid *GID) ForcePause() error {
-	return gid.client.ForcePause(gid.GID)
+	return gid.ForcePauseWithContext(context.Background())
+}
+
+// ForcePauseWithContext is like ForcePause() but the passed context can be used to cancel the call.
+func (gid *GID) ForcePauseWithContext(ctx context.Context) error {
+	return gid.client.ForcePauseWithContext(ctx, gid.GID)
 }
 
 // Unpause changes the status of the download from paused to waiting,
 // making the download eligible to be restarted.
 func (gid *GID) Unpause() error {
-	return gid.client.Unpause(gid.GID)
+	return gid.UnpauseWithContext(context.Background())
+}
+
+// UnpauseWithContext is like Unpause() but the passed context can be used to cancel the call.
+func (gid *GID) UnpauseWithContext(ctx context.Context) error {
+	return gid.client.UnpauseWithContext(ctx, gid.GID)
 }
 
 // TellStatus returns the progress of the download.
@@ -74,32 +184,93 @@ func (gid *GID) Unpause() error {
 // If keys is empty, the response contains all keys.
 // This is useful when you just want specific keys and avoid unnecessary transfers.
 func (gid *GID) TellStatus(keys ...string) (Status, error) {
-	return gid.client.TellStatus(gid.GID, keys...)
+	return gid.TellStatusWithContext(context.Background(), keys...)
+}
+
+// TellStatusWithContext is like TellStatus() but the passed context can be used to cancel the call.
+func (gid *GID) TellStatusWithContext(ctx context.Context, keys ...string) (Status, error) {
+	return gid.client.TellStatusWithContext(ctx, gid.GID, keys...)
+}
+
+// TellBitTorrentStatus returns the BitTorrent specific status of the download.
+// See Client.TellBitTorrentStatus() for details.
+func (gid *GID) TellBitTorrentStatus() (BitTorrentStatus, InfoHashSummary, error) {
+	return gid.TellBitTorrentStatusWithContext(context.Background())
+}
+
+// TellBitTorrentStatusWithContext is like TellBitTorrentStatus() but the passed context can be used to cancel the call.
+func (gid *GID) TellBitTorrentStatusWithContext(ctx context.Context) (BitTorrentStatus, InfoHashSummary, error) {
+	return gid.client.TellBitTorrentStatusWithContext(ctx, gid.GID)
+}
+
+// FollowedDownloads returns the status of every download which was generated as the result
+// of this download, see Status.FollowedBy.
+// keys does the same as in the TellStatus() method.
+func (gid *GID) FollowedDownloads(keys ...string) ([]Status, error) {
+	return gid.FollowedDownloadsWithContext(context.Background(), keys...)
+}
+
+// FollowedDownloadsWithContext is like FollowedDownloads() but the passed context can be used to cancel the call.
+func (gid *GID) FollowedDownloadsWithContext(ctx context.Context, keys ...string) ([]Status, error) {
+	return gid.client.FollowedDownloadsWithContext(ctx, gid.GID, keys...)
+}
+
+// ParentDownload returns the status of the download which this download is a part of,
+// see Status.BelongsTo.
+// If the download has no parent, nil is returned.
+// keys does the same as in the TellStatus() method.
+func (gid *GID) ParentDownload(keys ...string) (*Status, error) {
+	return gid.ParentDownloadWithContext(context.Background(), keys...)
+}
+
+// ParentDownloadWithContext is like ParentDownload() but the passed context can be used to cancel the call.
+func (gid *GID) ParentDownloadWithContext(ctx context.Context, keys ...string) (*Status, error) {
+	return gid.client.ParentDownloadWithContext(ctx, gid.GID, keys...)
 }
 
 // GetURIs returns the URIs used in the download.
 // The response is a slice of URI.
 func (gid *GID) GetURIs() ([]URI, error) {
-	return gid.client.GetURIs(gid.GID)
+	return gid.GetURIsWithContext(context.Background())
+}
+
+// GetURIsWithContext is like GetURIs() but the passed context can be used to cancel the call.
+func (gid *GID) GetURIsWithContext(ctx context.Context) ([]URI, error) {
+	return gid.client.GetURIsWithContext(ctx, gid.GID)
 }
 
 // GetFiles returns the file list of the download.
 // The response is a slice of File.
 func (gid *GID) GetFiles() ([]File, error) {
-	return gid.client.GetFiles(gid.GID)
+	return gid.GetFilesWithContext(context.Background())
+}
+
+// GetFilesWithContext is like GetFiles() but the passed context can be used to cancel the call.
+func (gid *GID) GetFilesWithContext(ctx context.Context) ([]File, error) {
+	return gid.client.GetFilesWithContext(ctx, gid.GID)
 }
 
 // GetPeers returns a list of peers of the download denoted by gid.
 // This method is for BitTorrent only.
 // The response is a slice of Peers.
 func (gid *GID) GetPeers() ([]Peer, error) {
-	return gid.client.GetPeers(gid.GID)
+	return gid.GetPeersWithContext(context.Background())
+}
+
+// GetPeersWithContext is like GetPeers() but the passed context can be used to cancel the call.
+func (gid *GID) GetPeersWithContext(ctx context.Context) ([]Peer, error) {
+	return gid.client.GetPeersWithContext(ctx, gid.GID)
 }
 
 // GetServers returns currently connected HTTP(S)/FTP/SFTP servers of the download denoted by gid.
 // Returns a slice of FileServers.
 func (gid *GID) GetServers() ([]FileServers, error) {
-	return gid.client.GetServers(gid.GID)
+	return gid.GetServersWithContext(context.Background())
+}
+
+// GetServersWithContext is like GetServers() but the passed context can be used to cancel the call.
+func (gid *GID) GetServersWithContext(ctx context.Context) ([]FileServers, error) {
+	return gid.client.GetServersWithContext(ctx, gid.GID)
 }
 
 // ChangePosition changes the position of the download denoted by gid in the queue.
@@ -107,19 +278,26 @@ func (gid *GID) GetServers() ([]FileServers, error) {
 // If how is SetPositionStart, it moves the download to a position relative to the beginning of the queue.
 // If how is SetPositionRelative, it moves the download to a position relative to the current position.
 // If how is SetPositionEnd, it moves the download to a position relative to the end of the queue.
+// pos may be negative for SetPositionRelative and SetPositionEnd to move the download towards
+// the beginning of the queue.
 // If the destination position is less than 0 or beyond the end of the queue,
 // it moves the download to the beginning or the end of the queue respectively.
+// If how isn't one of the above, ErrInvalidPositionBehaviour is returned without calling aria2.
 //
 // The response is an integer denoting the resulting position.
 func (gid *GID) ChangePosition(pos int, how PositionSetBehaviour) (int, error) {
-	return gid.client.ChangePosition(gid.GID, pos, how)
+	return gid.ChangePositionWithContext(context.Background(), pos, how)
+}
+
+// ChangePositionWithContext is like ChangePosition() but the passed context can be used to cancel the call.
+func (gid *GID) ChangePositionWithContext(ctx context.Context, pos int, how PositionSetBehaviour) (int, error) {
+	return gid.client.ChangePositionWithContext(ctx, gid.GID, pos, how)
 }
 
 // ChangeURIAt removes the URIs in delUris from and appends the URIs in addUris to download denoted by gid.
 // A download can contain multiple files and URIs are attached to each file.
 // fileIndex is used to select which file to remove/attach given URIs. fileIndex is 1-based.
 // position is used to specify where URIs are inserted in the existing waiting URI list. position is 0-based.
-// When position is nil, URIs are appended to the back of the list.
 //
 // This method first executes the removal and then the addition.
 // position is the position after URIs are removed, not the position when this method is called.
@@ -129,31 +307,45 @@ func (gid *GID) ChangePosition(pos int, how PositionSetBehaviour) (int, error) {
 // The first integer is the number of URIs deleted.
 // The second integer is the number of URIs added.
 func (gid *GID) ChangeURIAt(fileIndex uint, delURIs []string, addURIs []string, position uint) (uint, uint, error) {
-	return gid.client.ChangeURIAt(gid.GID, fileIndex, delURIs, addURIs, position)
+	return gid.ChangeURIAtWithContext(context.Background(), fileIndex, delURIs, addURIs, position)
+}
+
+// ChangeURIAtWithContext is like ChangeURIAt() but the passed context can be used to cancel the call.
+func (gid *GID) ChangeURIAtWithContext(ctx context.Context, fileIndex uint, delURIs []string, addURIs []string, position uint) (uint, uint, error) {
+	return gid.client.ChangeURIAtWithContext(ctx, gid.GID, fileIndex, delURIs, addURIs, position)
 }
 
 // ChangeURI removes the URIs in delUris from and appends the URIs in addUris to download denoted by gid.
 // A download can contain multiple files and URIs are attached to each file.
 // fileIndex is used to select which file to remove/attach given URIs. fileIndex is 1-based.
-// position is used to specify where URIs are inserted in the existing waiting URI list. position is 0-based.
-// URIs are appended to the back of the list.
+// URIs are appended to the back of the list. Use ChangeURIAt() to insert them at a specific position.
+// Both delURIs and addURIs may be nil.
 //
 // This method first executes the removal and then the addition.
-// position is the position after URIs are removed, not the position when this method is called.
 // When removing an URI, if the same URIs exist in download, only one of them is removed for each URI in delUris.
 //
 // Returns two integers.
 // The first integer is the number of URIs deleted.
 // The second integer is the number of URIs added.
 func (gid *GID) ChangeURI(fileIndex uint, delURIs []string, addURIs []string) (uint, uint, error) {
-	return gid.client.ChangeURI(gid.GID, fileIndex, delURIs, addURIs)
+	return gid.ChangeURIWithContext(context.Background(), fileIndex, delURIs, addURIs)
+}
+
+// ChangeURIWithContext is like ChangeURI() but the passed context can be used to cancel the call.
+func (gid *GID) ChangeURIWithContext(ctx context.Context, fileIndex uint, delURIs []string, addURIs []string) (uint, uint, error) {
+	return gid.client.ChangeURIWithContext(ctx, gid.GID, fileIndex, delURIs, addURIs)
 }
 
 // GetOptions returns Options of the download denoted by gid.
 // Note that this method does not return options which have no default value and have not been set on the command-line,
 // in configuration files or RPC methods.
 func (gid *GID) GetOptions() (Options, error) {
-	return gid.client.GetOptions(gid.GID)
+	return gid.GetOptionsWithContext(context.Background())
+}
+
+// GetOptionsWithContext is like GetOptions() but the passed context can be used to cancel the call.
+func (gid *GID) GetOptionsWithContext(ctx context.Context) (Options, error) {
+	return gid.client.GetOptionsWithContext(ctx, gid.GID)
 }
 
 // ChangeOptions changes options of the download denoted by gid dynamically.
@@ -175,10 +367,20 @@ func (gid *GID) GetOptions() (Options, error) {
 // 	- MaxDownloadLimit
 // 	- MaxUploadLimit
 func (gid *GID) ChangeOptions(changes Options) error {
-	return gid.client.ChangeOptions(gid.GID, changes)
+	return gid.ChangeOptionsWithContext(context.Background(), changes)
+}
+
+// ChangeOptionsWithContext is like ChangeOptions() but the passed context can be used to cancel the call.
+func (gid *GID) ChangeOptionsWithContext(ctx context.Context, changes Options) error {
+	return gid.client.ChangeOptionsWithContext(ctx, gid.GID, changes)
 }
 
 // RemoveDownloadResult removes a completed/error/removed download denoted by gid from memory.
 func (gid *GID) RemoveDownloadResult() error {
-	return gid.client.RemoveDownloadResult(gid.GID)
+	return gid.RemoveDownloadResultWithContext(context.Background())
+}
+
+// RemoveDownloadResultWithContext is like RemoveDownloadResult() but the passed context can be used to cancel the call.
+func (gid *GID) RemoveDownloadResultWithContext(ctx context.Context) error {
+	return gid.client.RemoveDownloadResultWithContext(ctx, gid.GID)
 }
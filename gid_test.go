@@ -0,0 +1,18 @@
+package arigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestIsValidGID(t *testing.T) {
+	assert.True(t, IsValidGID("2089b05ecca3d829"))
+	assert.True(t, IsValidGID("D2703803B52216D1"))
+	assert.False(t, IsValidGID(""))
+	assert.False(t, IsValidGID("2089b05ecca3d82"))
+	assert.False(t, IsValidGID("2089b05ecca3d82z"))
+
+	var client Client
+	gid := client.GetGID("2089b05ecca3d829")
+	assert.True(t, gid.IsValid())
+}
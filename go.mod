@@ -3,14 +3,6 @@ module github.com/jae-jae/arigo
 go 1.12
 
 require (
-	github.com/cenkalti/hub v1.0.1-0.20160527103212-11382a9960d3 // indirect
-	github.com/cenkalti/rpc2 v0.0.0-20180727162946-9642ea02d0aa
 	github.com/gorilla/websocket v1.4.0
 	github.com/stretchr/testify v1.3.0
-	golang.org/x/crypto v0.0.0-20190530122614-20be4c3c3ed5 // indirect
-	golang.org/x/lint v0.0.0-20190409202823-959b441ac422 // indirect
-	golang.org/x/net v0.0.0-20190522155817-f3200d17e092 // indirect
-	golang.org/x/sys v0.0.0-20190531175056-4c3a928424d2 // indirect
-	golang.org/x/text v0.3.2 // indirect
-	golang.org/x/tools v0.0.0-20190603152906-08e0b306e832 // indirect
 )
@@ -0,0 +1,354 @@
+// Package wsrpc provides a JSON-RPC 2.0 client which operates on a WebSocket
+// connection.
+package wsrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrClosed is returned by calls on a connection which was closed using Close.
+	ErrClosed = errors.New("connection closed")
+	// ErrConnectionLost is returned by calls on a connection which stopped unexpectedly.
+	ErrConnectionLost = errors.New("connection lost")
+	// ErrMessageTooLarge is returned by calls on a connection which stopped because
+	// the server sent a message exceeding the read limit of the WebSocket connection.
+	ErrMessageTooLarge = errors.New("message exceeds read limit")
+)
+
+// Error represents a JSON-RPC error object.
+type Error struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// NotificationHandler is called for every notification received from the server.
+type NotificationHandler func(method string, params json.RawMessage)
+
+// Direction tells whether a frame was sent or received.
+type Direction int
+
+const (
+	// Sent is the Direction of frames sent to the server.
+	Sent Direction = iota
+	// Received is the Direction of frames received from the server.
+	Received
+)
+
+func (d Direction) String() string {
+	if d == Sent {
+		return "sent"
+	}
+
+	return "received"
+}
+
+type request struct {
+	JSONRPC string        `json:"jsonrpc"`
+	ID      string        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type message struct {
+	ID     json.RawMessage `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *Error          `json:"error"`
+}
+
+// Client is a JSON-RPC client using a WebSocket connection.
+// It is safe to use from multiple goroutines.
+type Client struct {
+	ws *websocket.Conn
+
+	writeMut sync.Mutex
+
+	mut     sync.Mutex
+	pending map[string]chan *message
+	seq     uint64
+	closed  bool
+	err     error
+
+	done chan struct{}
+
+	// ErrorHandler is called for received messages which can't be handled,
+	// for example because they can't be decoded. It must be set before Run is called.
+	ErrorHandler func(err error)
+
+	// FrameHandler is called with every frame which is sent or received.
+	// It must not modify or retain the frame. It must be set before the client is used.
+	FrameHandler func(dir Direction, frame []byte)
+
+	// IDGenerator returns the id of every request. By default ids are consecutive numbers.
+	// The ids of in-flight requests must be unique, calls for which IDGenerator
+	// returns the id of a pending request fail.
+	// It's never called concurrently. It must be set before the client is used.
+	IDGenerator func() string
+}
+
+// reportError passes err to the ErrorHandler, if there is one.
+func (c *Client) reportError(err error) {
+	if c.ErrorHandler != nil {
+		c.ErrorHandler(err)
+	}
+}
+
+// NewClient creates a new client from a WebSocket connection.
+// The client only receives responses while Run is running.
+func NewClient(ws *websocket.Conn) *Client {
+	return &Client{
+		ws:      ws,
+		pending: make(map[string]chan *message),
+		done:    make(chan struct{}),
+	}
+}
+
+// Run reads incoming messages until the connection is closed.
+// The handler is called for all notifications sent by the server, it may be nil.
+// Run returns the error which caused the connection to stop.
+func (c *Client) Run(handler NotificationHandler) error {
+	for {
+		_, data, err := c.ws.ReadMessage()
+		if err == websocket.ErrReadLimit {
+			c.shutdown(ErrMessageTooLarge)
+			return err
+		} else if err != nil {
+			c.shutdown(ErrConnectionLost)
+			return err
+		}
+
+		if c.FrameHandler != nil {
+			c.FrameHandler(Received, data)
+		}
+
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			c.reportError(fmt.Errorf("couldn't decode message: %v", err))
+			continue
+		}
+
+		if msg.Method != "" {
+			if handler != nil {
+				go handler(msg.Method, msg.Params)
+			}
+
+			continue
+		}
+
+		c.mut.Lock()
+		ch, ok := c.pending[decodeID(msg.ID)]
+		c.mut.Unlock()
+
+		if !ok {
+			// the call might have been abandoned already
+			c.reportError(fmt.Errorf("received response for unknown call %s", msg.ID))
+			continue
+		}
+
+		select {
+		case ch <- &msg:
+		default:
+			// a response for this id was already received
+		}
+	}
+}
+
+// KeepAlive sends a ping frame every interval until the connection is closed.
+// If no pong arrives within timeout after a ping, the connection is considered lost.
+// It must be called before Run.
+func (c *Client) KeepAlive(interval, timeout time.Duration) {
+	extendDeadline := func() error {
+		return c.ws.SetReadDeadline(time.Now().Add(interval + timeout))
+	}
+
+	c.ws.SetPongHandler(func(string) error {
+		return extendDeadline()
+	})
+	_ = extendDeadline()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.done:
+				return
+			case <-ticker.C:
+				// a failed ping doesn't need to be handled here,
+				// the read deadline takes care of it.
+				_ = c.ws.WriteControl(websocket.PingMessage, nil, time.Now().Add(timeout))
+			}
+		}
+	}()
+}
+
+func decodeID(raw json.RawMessage) string {
+	var id string
+	if err := json.Unmarshal(raw, &id); err != nil {
+		return string(raw)
+	}
+
+	return id
+}
+
+// shutdown marks the connection as closed.
+// err is returned by all pending and future calls.
+func (c *Client) shutdown(err error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		c.err = err
+		close(c.done)
+	}
+}
+
+// Err returns the error returned by calls after the connection was closed.
+// It returns nil while the connection is open.
+func (c *Client) Err() error {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	return c.err
+}
+
+// Done returns a channel which is closed once the connection is closed.
+func (c *Client) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *Client) register() (string, chan *message, error) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	if c.closed {
+		return "", nil, c.err
+	}
+
+	var id string
+	if c.IDGenerator != nil {
+		id = c.IDGenerator()
+		if _, ok := c.pending[id]; ok {
+			return "", nil, fmt.Errorf("duplicate request id %q", id)
+		}
+	} else {
+		c.seq++
+		id = strconv.FormatUint(c.seq, 10)
+	}
+
+	ch := make(chan *message, 1)
+	c.pending[id] = ch
+
+	return id, ch, nil
+}
+
+func (c *Client) unregister(id string) {
+	c.mut.Lock()
+	delete(c.pending, id)
+	c.mut.Unlock()
+}
+
+func (c *Client) write(ctx context.Context, data []byte) error {
+	c.writeMut.Lock()
+	defer c.writeMut.Unlock()
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	deadline, _ := ctx.Deadline()
+	if err := c.ws.SetWriteDeadline(deadline); err != nil {
+		return err
+	}
+
+	if c.FrameHandler != nil {
+		c.FrameHandler(Sent, data)
+	}
+
+	return c.ws.WriteMessage(websocket.TextMessage, data)
+}
+
+// idReporterKey is the context key of the function passed to WithIDReporter.
+type idReporterKey struct{}
+
+// WithIDReporter returns a copy of ctx which makes Call pass the id
+// of the request to report before the request is sent.
+func WithIDReporter(ctx context.Context, report func(id string)) context.Context {
+	return context.WithValue(ctx, idReporterKey{}, report)
+}
+
+// Call calls the method with the given params and waits for the response.
+// If reply isn't nil, the result is unmarshalled into it.
+//
+// When ctx is done before the response arrives, the call is abandoned
+// and the error of the context is returned.
+// If the server responded with an error, it is returned as an *Error.
+func (c *Client) Call(ctx context.Context, method string, params []interface{}, reply interface{}) error {
+	id, ch, err := c.register()
+	if err != nil {
+		return err
+	}
+	defer c.unregister(id)
+
+	if report, ok := ctx.Value(idReporterKey{}).(func(id string)); ok {
+		report(id)
+	}
+
+	if params == nil {
+		params = []interface{}{}
+	}
+
+	data, err := json.Marshal(request{JSONRPC: "2.0", ID: id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	if err := c.write(ctx, data); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-c.done:
+		return c.Err()
+	case msg := <-ch:
+		if msg.Error != nil {
+			return msg.Error
+		}
+
+		if reply == nil || len(msg.Result) == 0 {
+			return nil
+		}
+
+		return json.Unmarshal(msg.Result, reply)
+	}
+}
+
+// Close closes the underlying WebSocket connection.
+// Pending calls return ErrClosed.
+func (c *Client) Close() error {
+	c.writeMut.Lock()
+	_ = c.ws.WriteControl(websocket.CloseMessage,
+		websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	c.writeMut.Unlock()
+
+	c.shutdown(ErrClosed)
+	return c.ws.Close()
+}
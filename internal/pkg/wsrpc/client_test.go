@@ -0,0 +1,273 @@
+package wsrpc
+
+import (
+	"context"
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startServer starts a WebSocket server which passes every received request to respond
+// and sends back the returned message, if any.
+func startServer(t *testing.T, respond func(req map[string]interface{}) interface{}) *Client {
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		for {
+			var req map[string]interface{}
+			if err := ws.ReadJSON(&req); err != nil {
+				return
+			}
+
+			if resp := respond(req); resp != nil {
+				_ = ws.WriteJSON(resp)
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+
+	client := NewClient(ws)
+	go client.Run(nil)
+	t.Cleanup(func() { _ = client.Close() })
+
+	return client
+}
+
+func TestClientCall(t *testing.T) {
+	client := startServer(t, func(req map[string]interface{}) interface{} {
+		return map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": req["params"]}
+	})
+
+	var reply []string
+	require.NoError(t, client.Call(context.Background(), "echo", []interface{}{"a", "b"}, &reply))
+	assert.Equal(t, []string{"a", "b"}, reply)
+}
+
+func TestClientCallError(t *testing.T) {
+	client := startServer(t, func(req map[string]interface{}) interface{} {
+		return map[string]interface{}{
+			"jsonrpc": "2.0",
+			"id":      req["id"],
+			"error":   map[string]interface{}{"code": 1, "message": "GID 2089b05ecca3d829 is not found"},
+		}
+	})
+
+	err := client.Call(context.Background(), "aria2.tellStatus", nil, nil)
+	require.IsType(t, &Error{}, err)
+	assert.Equal(t, 1, err.(*Error).Code)
+	assert.Equal(t, "GID 2089b05ecca3d829 is not found", err.Error())
+}
+
+func TestClientCallCancel(t *testing.T) {
+	client := startServer(t, func(req map[string]interface{}) interface{} {
+		if req["method"] == "slow" {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		return map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": "OK"}
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	err := client.Call(ctx, "slow", nil, nil)
+	assert.Equal(t, context.DeadlineExceeded, err)
+
+	client.mut.Lock()
+	assert.Empty(t, client.pending, "pending call should be removed")
+	client.mut.Unlock()
+
+	// the late response of the first call must be discarded
+	var reply string
+	require.NoError(t, client.Call(context.Background(), "fast", nil, &reply))
+	assert.Equal(t, "OK", reply)
+}
+
+func TestClientClosed(t *testing.T) {
+	client := startServer(t, func(req map[string]interface{}) interface{} {
+		return nil
+	})
+
+	require.NoError(t, client.Close())
+	assert.Equal(t, ErrClosed, client.Call(context.Background(), "aria2.tellStatus", nil, nil))
+}
+
+func TestClientKeepAlive(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	pings := make(chan struct{}, 10)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		answer := r.URL.Query().Get("answer") != ""
+		ws.SetPingHandler(func(data string) error {
+			pings <- struct{}{}
+			if !answer {
+				return nil
+			}
+
+			return ws.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+		})
+
+		for {
+			if _, _, err := ws.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	dial := func(query string) *Client {
+		ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+query, nil)
+		require.NoError(t, err)
+
+		client := NewClient(ws)
+		client.KeepAlive(10*time.Millisecond, 20*time.Millisecond)
+		go client.Run(nil)
+
+		return client
+	}
+
+	client := dial("?answer=1")
+	time.Sleep(100 * time.Millisecond)
+	assert.NoError(t, client.Err(), "connection should stay alive while pongs are received")
+	assert.NotEmpty(t, pings)
+	require.NoError(t, client.Close())
+
+	client = dial("")
+	select {
+	case <-client.Done():
+		assert.Equal(t, ErrConnectionLost, client.Err())
+	case <-time.After(time.Second):
+		t.Fatal("connection wasn't considered lost")
+	}
+}
+
+func TestClientErrorHandler(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		_ = ws.WriteMessage(websocket.TextMessage, []byte("not json"))
+		_ = ws.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": "42", "result": "OK"})
+		_, _, _ = ws.ReadMessage()
+	}))
+	defer server.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+
+	errs := make(chan error, 2)
+	client := NewClient(ws)
+	client.ErrorHandler = func(err error) {
+		errs <- err
+	}
+	go client.Run(nil)
+	defer client.Close()
+
+	assert.Contains(t, (<-errs).Error(), "couldn't decode message")
+	assert.Equal(t, `received response for unknown call "42"`, (<-errs).Error())
+}
+
+func TestClientFrameHandler(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		var req map[string]interface{}
+		if err := ws.ReadJSON(&req); err != nil {
+			return
+		}
+		_ = ws.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":"1","result":"OK"}`))
+		_, _, _ = ws.ReadMessage()
+	}))
+	defer server.Close()
+
+	ws, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	require.NoError(t, err)
+
+	frames := make(chan string, 2)
+	client := NewClient(ws)
+	client.FrameHandler = func(dir Direction, frame []byte) {
+		frames <- dir.String() + " " + string(frame)
+	}
+	go client.Run(nil)
+	defer client.Close()
+
+	require.NoError(t, client.Call(context.Background(), "echo", []interface{}{"a"}, nil))
+
+	assert.Equal(t, `sent {"jsonrpc":"2.0","id":"1","method":"echo","params":["a"]}`, <-frames)
+	assert.Equal(t, `received {"jsonrpc":"2.0","id":"1","result":"OK"}`, <-frames)
+}
+
+func TestClientIDGenerator(t *testing.T) {
+	client := startServer(t, func(req map[string]interface{}) interface{} {
+		if req["method"] == "hang" {
+			return nil
+		}
+
+		return map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": req["id"]}
+	})
+
+	ids := []string{"trace-a", "trace-b", "trace-b"}
+	client.IDGenerator = func() string {
+		id := ids[0]
+		ids = ids[1:]
+		return id
+	}
+
+	var reply string
+	require.NoError(t, client.Call(context.Background(), "echo", nil, &reply))
+	assert.Equal(t, "trace-a", reply)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	hung := make(chan error, 1)
+	go func() {
+		hung <- client.Call(ctx, "hang", nil, nil)
+	}()
+
+	// wait for the hanging call to be registered
+	for {
+		client.mut.Lock()
+		pending := len(client.pending)
+		client.mut.Unlock()
+
+		if pending > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	assert.EqualError(t, client.Call(context.Background(), "echo", nil, &reply), `duplicate request id "trace-b"`)
+
+	cancel()
+	assert.Equal(t, context.Canceled, <-hung)
+}
@@ -0,0 +1,35 @@
+package arigo
+
+import "time"
+
+// Metrics receives measurements taken by a Client.
+// It can be set using the WithMetrics() option.
+// Implementations must be safe to use from multiple goroutines.
+type Metrics interface {
+	// ObserveCall is called after every call made to aria2 with the name of the method,
+	// the time it took and the error of the call, if any.
+	// Calls which are retried are observed once per attempt.
+	ObserveCall(method string, latency time.Duration, err error)
+
+	// IncReconnects is called whenever a lost connection was re-established.
+	IncReconnects()
+
+	// IncNotifications is called for every notification received from aria2
+	// with the name of its method, e.g. "aria2.onDownloadStart".
+	IncNotifications(method string)
+
+	// IncDroppedEvents is called for every event a Subscription discarded
+	// because of its OverflowPolicy.
+	IncDroppedEvents(evtType EventType)
+}
+
+// noopMetrics is the Metrics used by default. It discards all measurements.
+type noopMetrics struct{}
+
+func (noopMetrics) ObserveCall(string, time.Duration, error) {}
+
+func (noopMetrics) IncReconnects() {}
+
+func (noopMetrics) IncNotifications(string) {}
+
+func (noopMetrics) IncDroppedEvents(EventType) {}
@@ -0,0 +1,64 @@
+package arigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingMetrics struct {
+	mut           sync.Mutex
+	calls         []string
+	errs          []error
+	notifications []string
+	dropped       []EventType
+}
+
+func (m *recordingMetrics) ObserveCall(method string, latency time.Duration, err error) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	m.calls = append(m.calls, method)
+	m.errs = append(m.errs, err)
+}
+
+func (m *recordingMetrics) IncReconnects() {}
+
+func (m *recordingMetrics) IncDroppedEvents(evtType EventType) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	m.dropped = append(m.dropped, evtType)
+}
+
+func (m *recordingMetrics) IncNotifications(method string) {
+	m.mut.Lock()
+	defer m.mut.Unlock()
+
+	m.notifications = append(m.notifications, method)
+}
+
+func TestWithMetrics(t *testing.T) {
+	metrics := &recordingMetrics{}
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		if method == "aria2.remove" {
+			return &RPCError{Code: 1, Message: "not found"}
+		}
+
+		return "OK"
+	}, WithMetrics(metrics))
+
+	assert.NoError(t, client.SaveSession())
+	assert.Error(t, client.Remove("1"))
+
+	client.handleNotification("aria2.onDownloadStart", []byte(`[{"gid": "1"}]`))
+
+	metrics.mut.Lock()
+	defer metrics.mut.Unlock()
+
+	assert.Equal(t, []string{"aria2.saveSession", "aria2.remove"}, metrics.calls)
+	assert.NoError(t, metrics.errs[0])
+	assert.Error(t, metrics.errs[1])
+	assert.Equal(t, []string{"aria2.onDownloadStart"}, metrics.notifications)
+}
@@ -4,15 +4,9 @@ import (
 	"encoding/json"
 )
 
-// MethodCallError represents an error returned by aria2 for a MethodCall
-type MethodCallError struct {
-	Code    uint   `json:"code,string"`
-	Message string `json:"message"`
-}
-
-func (e *MethodCallError) Error() string {
-	return e.Message
-}
+// MethodCallError represents an error returned by aria2 for a MethodCall.
+// It's the same as the RPCError returned by regular calls.
+type MethodCallError = RPCError
 
 // MethodResult represents the result of a MethodCall
 // in a MultiCall operation.
@@ -21,10 +15,34 @@ type MethodResult struct {
 
 	// Error encountered during the method call.
 	// This is likely to be a MethodCallError but it's
-	// not guaranteed.
+	// not guaranteed. nil if the call succeeded.
 	Error error
 }
 
+// parseMethodResults converts the raw results of a multicall.
+// aria2 wraps every successful result in a single element array
+// and returns a fault object for failed calls.
+func parseMethodResults(rawResults []json.RawMessage) []MethodResult {
+	results := make([]MethodResult, len(rawResults))
+
+	for i, rawResult := range rawResults {
+		var values []json.RawMessage
+		if err := json.Unmarshal(rawResult, &values); err == nil && len(values) == 1 {
+			results[i] = MethodResult{Result: values[0]}
+			continue
+		}
+
+		methodErr := &MethodCallError{}
+		if err := json.Unmarshal(rawResult, methodErr); err != nil {
+			results[i] = MethodResult{Error: err}
+		} else {
+			results[i] = MethodResult{Error: methodErr}
+		}
+	}
+
+	return results
+}
+
 // Unmarshal unmarshals the raw result into v.
 // If the result contains an error, it is returned directly
 // without ever even attempting to unmarshal the result.
@@ -0,0 +1,34 @@
+package arigo
+
+import (
+	"encoding/json"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestMethodResultsFormat(t *testing.T) {
+	data := []byte(`[
+		[{"gid": "2089b05ecca3d829", "status": "active"}],
+		{"code": 1, "message": "GID 0000000000000001 is not found"},
+		["OK"]
+	]`)
+
+	var rawResults []json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &rawResults), "Couldn't unmarshal JSON")
+
+	results := parseMethodResults(rawResults)
+	require.Len(t, results, 3)
+
+	var status Status
+	assert.NoError(t, results[0].Unmarshal(&status))
+	assert.Equal(t, "2089b05ecca3d829", status.GID)
+	assert.Equal(t, StatusActive, status.Status)
+
+	err := results[1].Unmarshal(&status)
+	assert.Equal(t, &MethodCallError{Code: 1, Message: "GID 0000000000000001 is not found"}, err)
+
+	var reply string
+	assert.NoError(t, results[2].Unmarshal(&reply))
+	assert.Equal(t, "OK", reply)
+}
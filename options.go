@@ -1,115 +1,551 @@
 package arigo
 
-// Options represents the aria2 input file options
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ByteSize represents a size or a speed measured in bytes.
+type ByteSize uint64
+
+const (
+	// KiB is the size of a kibibyte (1024 bytes).
+	KiB ByteSize = 1 << (10 * (iota + 1))
+	// MiB is the size of a mebibyte (1024 kibibytes).
+	MiB
+	// GiB is the size of a gibibyte (1024 mebibytes).
+	GiB
+)
+
+// ParseSize parses a size the way aria2 does.
+// The number may be followed by the suffix K or M (case-insensitive)
+// to specify kibibytes or mebibytes, e.g. "500K" or "1M".
+func ParseSize(s string) (ByteSize, error) {
+	raw := strings.TrimSpace(s)
+
+	unit := ByteSize(1)
+	if raw != "" {
+		switch raw[len(raw)-1] {
+		case 'K', 'k':
+			unit = KiB
+		case 'M', 'm':
+			unit = MiB
+		}
+	}
+
+	if unit != 1 {
+		raw = raw[:len(raw)-1]
+	}
+
+	n, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q", s)
+	}
+
+	if n > math.MaxUint64/uint64(unit) {
+		return 0, fmt.Errorf("size %q is too large", s)
+	}
+
+	return ByteSize(n) * unit, nil
+}
+
+// Uint returns a pointer to v.
+// This is a convenience function for setting the optional numeric fields of Options.
+func Uint(v uint) *uint {
+	return &v
+}
+
+// Size returns a pointer to v.
+// This is a convenience function for setting the optional size fields of Options.
+func Size(v ByteSize) *ByteSize {
+	return &v
+}
+
+// Duration returns a pointer to d.
+// This is a convenience function for setting the optional duration fields of Options.
+func Duration(d time.Duration) *time.Duration {
+	return &d
+}
+
+// Bool returns a pointer to v.
+// This is a convenience function for setting the optional boolean fields of Options.
+func Bool(v bool) *bool {
+	return &v
+}
+
+// Float returns a pointer to v.
+// This is a convenience function for setting the optional floating point fields of Options.
+func Float(v float64) *float64 {
+	return &v
+}
+
+// Options represents the aria2 input file options.
+//
+// Numeric and boolean fields are pointers so that a value of zero or false
+// can be distinguished from an unset value.
+// Unset fields are omitted when the options are sent to aria2.
+// Durations are sent with a resolution of seconds unless documented otherwise.
+//
+// Options which aren't known to arigo can be passed in Extra.
 type Options struct {
-	AllProxy                      string  `json:"all-proxy,omitempty"`
-	AllProxyPassword              string  `json:"all-proxy-passwd,omitempty"`
-	AllProxyUser                  string  `json:"all-proxy-user,omitempty"`
-	AllowOverwrite                bool    `json:"allow-overwrite,omitempty,string"`
-	AllowPieceLengthChange        bool    `json:"allow-piece-length-change,omitempty,string"`
-	AlwaysResume                  bool    `json:"always-resume,omitempty,string"`
-	AsyncDNS                      bool    `json:"async-dns,omitempty,string"`
-	AutoFileRenaming              bool    `json:"auto-file-renaming,omitempty,string"`
-	BTEnableHookAfterHashCheck    bool    `json:"bt-enable-hook-after-hash-check,omitempty,string"`
-	BTEnableLpd                   bool    `json:"bt-enable-lpd,omitempty,string"`
-	BTExcludeTracker              string  `json:"bt-exclude-tracker,omitempty"`
-	BTExternalIP                  string  `json:"bt-external-ip,omitempty"`
-	BTForceEncryption             bool    `json:"bt-force-encryption,omitempty,string"`
-	BTHashCheckSeed               bool    `json:"bt-hash-check-seed,omitempty,string"`
-	BTLoadSavedMetadata           bool    `json:"bt-load-saved-metadata,omitempty,string"`
-	BTMaxPeers                    uint    `json:"bt-max-peers,omitempty,string"`
-	BTMetadataOnly                bool    `json:"bt-metadata-only,omitempty,string"`
-	BTMinCryptoLevel              string  `json:"bt-min-crypto-level,omitempty"`
-	BTPrioritizePiece             string  `json:"bt-prioritize-piece,omitempty"`
-	BTRemoveUnselectedFile        bool    `json:"bt-remove-unselected-file,omitempty,string"`
-	BTRequestPeerSpeedLimit       string  `json:"bt-request-peer-speed-limit,omitempty"`
-	BTRequireCrypto               bool    `json:"bt-require-crypto,omitempty,string"`
-	BTSaveMetadata                bool    `json:"bt-save-metadata,omitempty,string"`
-	BTSeedUnverified              bool    `json:"bt-seed-unverified,omitempty,string"`
-	BTStopTimeout                 uint    `json:"bt-stop-timeout,omitempty,string"`
-	BTTracker                     string  `json:"bt-tracker,omitempty"`
-	BTTrackerConnectTimeout       uint    `json:"bt-tracker-connect-timeout,omitempty,string"`
-	BTTrackerInterval             uint    `json:"bt-tracker-interval,omitempty,string"`
-	BTTrackerTimeout              uint    `json:"bt-tracker-timeout,omitempty,string"`
-	CheckIntegrity                bool    `json:"check-integrity,omitempty,string"`
-	Checksum                      string  `json:"checksum,omitempty"`
-	ConditionalGet                bool    `json:"conditional-get,omitempty,string"`
-	ConnectTimeout                uint    `json:"connect-timeout,omitempty,string"`
-	ContentDispositionDefaultUTF8 bool    `json:"content-disposition-default-utf8,omitempty,string"`
-	Continue                      bool    `json:"continue,omitempty,string"`
-	Dir                           string  `json:"dir,omitempty"`
-	DryRun                        bool    `json:"dry-run,omitempty,string"`
-	EnableHTTPKeepAlive           bool    `json:"enable-http-keep-alive,omitempty,string"`
-	EnableHTTPPipelining          bool    `json:"enable-http-pipelining,omitempty,string"`
-	EnableMMap                    bool    `json:"enable-mmap,omitempty,string"`
-	EnablePeerExchange            bool    `json:"enable-peer-exchange,omitempty,string"`
-	FileAllocation                string  `json:"file-allocation,omitempty"`
-	FollowMetalink                bool    `json:"follow-metalink,omitempty,string"`
-	FollowTorrent                 bool    `json:"follow-torrent,omitempty,string"`
-	ForceSave                     bool    `json:"force-save,omitempty,string"`
-	FTPPasswd                     string  `json:"ftp-passwd,omitempty"`
-	FTPPasv                       bool    `json:"ftp-pasv,omitempty,string"`
-	FTPProxy                      string  `json:"ftp-proxy,omitempty"`
-	FTPProxyPasswd                string  `json:"ftp-proxy-passwd,omitempty"`
-	FTPProxyUser                  string  `json:"ftp-proxy-user,omitempty"`
-	FTPReuseConnection            bool    `json:"ftp-reuse-connection,omitempty,string"`
-	FTPType                       string  `json:"ftp-type,omitempty"`
-	FTPUser                       string  `json:"ftp-user,omitempty"`
-	GID                           string  `json:"gid,omitempty"`
-	HashCheckOnly                 bool    `json:"hash-check-only,omitempty,string"`
-	Header                        string  `json:"header,omitempty"`
-	HTTPAcceptGzip                bool    `json:"http-accept-gzip,omitempty,string"`
-	HTTPAuthChallenge             bool    `json:"http-auth-challenge,omitempty,string"`
-	HTTPNoCache                   bool    `json:"http-no-cache,omitempty,string"`
-	HTTPPasswd                    string  `json:"http-passwd,omitempty"`
-	HTTPProxy                     string  `json:"http-proxy,omitempty"`
-	HTTPProxyPasswd               string  `json:"http-proxy-passwd,omitempty"`
-	HTTPProxyUser                 string  `json:"http-proxy-user,omitempty"`
-	HTTPUser                      string  `json:"http-user,omitempty"`
-	HTTPSProxy                    string  `json:"https-proxy,omitempty"`
-	HTTPSProxyPasswd              string  `json:"https-proxy-passwd,omitempty"`
-	HTTPSProxyUser                string  `json:"https-proxy-user,omitempty"`
-	IndexOut                      uint    `json:"index-out,omitempty,string"`
-	LowestSpeedLimit              uint    `json:"lowest-speed-limit,omitempty,string"`
-	MaxConnectionPerServer        uint    `json:"max-connection-per-server,omitempty,string"`
-	MaxDownloadLimit              uint    `json:"max-download-limit,omitempty,string"`
-	MaxFileNotFound               uint    `json:"max-file-not-found,omitempty,string"`
-	MaxMMapLimit                  uint    `json:"max-mmap-limit,omitempty,string"`
-	MaxResumeFailureTries         uint    `json:"max-resume-failure-tries,omitempty,string"`
-	MaxTries                      uint    `json:"max-tries,omitempty,string"`
-	MaxUploadLimit                uint    `json:"max-upload-limit,omitempty,string"`
-	MetalinkBaseURI               string  `json:"metalink-base-uri,omitempty"`
-	MetalinkEnableUniqueProtocol  bool    `json:"metalink-enable-unique-protocol,omitempty,string"`
-	MetalinkLanguage              string  `json:"metalink-language,omitempty"`
-	MetalinkLocation              string  `json:"metalink-location,omitempty"`
-	MetalinkOS                    string  `json:"metalink-os,omitempty"`
-	MetalinkPreferredProtocol     string  `json:"metalink-preferred-protocol,omitempty"`
-	MetalinkVersion               string  `json:"metalink-version,omitempty"`
-	MinSplitSize                  uint    `json:"min-split-size,omitempty,string"`
-	NoFileAllocationLimit         bool    `json:"no-file-allocation-limit,omitempty,string"`
-	NoNetrc                       bool    `json:"no-netrc,omitempty,string"`
-	NoProxy                       bool    `json:"no-proxy,omitempty,string"`
-	Out                           string  `json:"out,omitempty"`
-	ParameterizedURI              string  `json:"parameterized-uri,omitempty"`
-	Pause                         bool    `json:"pause,omitempty,string"`
-	PauseMetadata                 bool    `json:"pause-metadata,omitempty,string"`
-	PieceLength                   string  `json:"piece-length,omitempty"`
-	ProxyMethod                   string  `json:"proxy-method,omitempty"`
-	RealtimeChunkChecksum         string  `json:"realtime-chunk-checksum,omitempty"`
-	Referer                       string  `json:"referer,omitempty"`
-	RemoteTime                    bool    `json:"remote-time,omitempty,string"`
-	RemoveControlFile             string  `json:"remove-control-file,omitempty"`
-	RetryWait                     uint    `json:"retry-wait,omitempty,string"`
-	ReuseURI                      bool    `json:"reuse-uri,omitempty,string"`
-	RPCSaveUploadMetadata         string  `json:"rpc-save-upload-metadata,omitempty"`
-	SeedRatio                     float32 `json:"seed-ratio,omitempty,string"`
-	SeedTime                      uint    `json:"seed-time,omitempty,string"`
-	SelectFile                    bool    `json:"select-file,omitempty,string"`
-	Split                         uint    `json:"split,omitempty,string"`
-	SSHHostKeyMD                  string  `json:"ssh-host-key-md,omitempty"`
-	StreamPieceSelector           string  `json:"stream-piece-selector,omitempty"`
-	Timeout                       uint    `json:"timeout,omitempty,string"`
-	URISelector                   string  `json:"uri-selector,omitempty"`
-	UseHead                       bool    `json:"use-head,omitempty,string"`
-	UserAgent                     string  `json:"user-agent,omitempty"`
+	AllProxy                      string         `json:"all-proxy"`
+	AllProxyPassword              string         `json:"all-proxy-passwd"`
+	AllProxyUser                  string         `json:"all-proxy-user"`
+	AllowOverwrite                *bool          `json:"allow-overwrite"`
+	AllowPieceLengthChange        *bool          `json:"allow-piece-length-change"`
+	AlwaysResume                  *bool          `json:"always-resume"`
+	AsyncDNS                      *bool          `json:"async-dns"`
+	AutoFileRenaming              *bool          `json:"auto-file-renaming"`
+	BTEnableHookAfterHashCheck    *bool          `json:"bt-enable-hook-after-hash-check"`
+	BTEnableLpd                   *bool          `json:"bt-enable-lpd"`
+	BTExcludeTracker              string         `json:"bt-exclude-tracker"`
+	BTExternalIP                  string         `json:"bt-external-ip"`
+	BTForceEncryption             *bool          `json:"bt-force-encryption"`
+	BTHashCheckSeed               *bool          `json:"bt-hash-check-seed"`
+	BTLoadSavedMetadata           *bool          `json:"bt-load-saved-metadata"`
+	BTMaxPeers                    *uint          `json:"bt-max-peers"`
+	BTMetadataOnly                *bool          `json:"bt-metadata-only"`
+	BTMinCryptoLevel              string         `json:"bt-min-crypto-level"`
+	BTPrioritizePiece             string         `json:"bt-prioritize-piece"`
+	BTRemoveUnselectedFile        *bool          `json:"bt-remove-unselected-file"`
+	BTRequestPeerSpeedLimit       *ByteSize      `json:"bt-request-peer-speed-limit"`
+	BTRequireCrypto               *bool          `json:"bt-require-crypto"`
+	BTSaveMetadata                *bool          `json:"bt-save-metadata"`
+	BTSeedUnverified              *bool          `json:"bt-seed-unverified"`
+	BTStopTimeout                 *time.Duration `json:"bt-stop-timeout"`
+	BTTracker                     string         `json:"bt-tracker"`
+	BTTrackerConnectTimeout       *time.Duration `json:"bt-tracker-connect-timeout"`
+	BTTrackerInterval             *time.Duration `json:"bt-tracker-interval"`
+	BTTrackerTimeout              *time.Duration `json:"bt-tracker-timeout"`
+	CheckIntegrity                *bool          `json:"check-integrity"`
+	Checksum                      string         `json:"checksum"`
+	ConditionalGet                *bool          `json:"conditional-get"`
+	ConnectTimeout                *time.Duration `json:"connect-timeout"`
+	ContentDispositionDefaultUTF8 *bool          `json:"content-disposition-default-utf8"`
+	Continue                      *bool          `json:"continue"`
+	Dir                           string         `json:"dir"`
+	DryRun                        *bool          `json:"dry-run"`
+	EnableHTTPKeepAlive           *bool          `json:"enable-http-keep-alive"`
+	EnableHTTPPipelining          *bool          `json:"enable-http-pipelining"`
+	EnableMMap                    *bool          `json:"enable-mmap"`
+	EnablePeerExchange            *bool          `json:"enable-peer-exchange"`
+	FileAllocation                string         `json:"file-allocation"`
+	FollowMetalink                *bool          `json:"follow-metalink"`
+	FollowTorrent                 *bool          `json:"follow-torrent"`
+	ForceSave                     *bool          `json:"force-save"`
+	FTPPasswd                     string         `json:"ftp-passwd"`
+	FTPPasv                       *bool          `json:"ftp-pasv"`
+	FTPProxy                      string         `json:"ftp-proxy"`
+	FTPProxyPasswd                string         `json:"ftp-proxy-passwd"`
+	FTPProxyUser                  string         `json:"ftp-proxy-user"`
+	FTPReuseConnection            *bool          `json:"ftp-reuse-connection"`
+	FTPType                       string         `json:"ftp-type"`
+	FTPUser                       string         `json:"ftp-user"`
+	GID                           string         `json:"gid"`
+	HashCheckOnly                 *bool          `json:"hash-check-only"`
+	Header                        []string       `json:"header"`
+	HTTPAcceptGzip                *bool          `json:"http-accept-gzip"`
+	HTTPAuthChallenge             *bool          `json:"http-auth-challenge"`
+	HTTPNoCache                   *bool          `json:"http-no-cache"`
+	HTTPPasswd                    string         `json:"http-passwd"`
+	HTTPProxy                     string         `json:"http-proxy"`
+	HTTPProxyPasswd               string         `json:"http-proxy-passwd"`
+	HTTPProxyUser                 string         `json:"http-proxy-user"`
+	HTTPUser                      string         `json:"http-user"`
+	HTTPSProxy                    string         `json:"https-proxy"`
+	HTTPSProxyPasswd              string         `json:"https-proxy-passwd"`
+	HTTPSProxyUser                string         `json:"https-proxy-user"`
+	IndexOut                      []string       `json:"index-out"` // Entries of the form INDEX=PATH, see SetIndexOut()
+	LowestSpeedLimit              *ByteSize      `json:"lowest-speed-limit"`
+	MaxConnectionPerServer        *uint          `json:"max-connection-per-server"`
+	MaxDownloadLimit              *ByteSize      `json:"max-download-limit"`
+	MaxFileNotFound               *uint          `json:"max-file-not-found"`
+	MaxMMapLimit                  *ByteSize      `json:"max-mmap-limit"`
+	MaxResumeFailureTries         *uint          `json:"max-resume-failure-tries"`
+	MaxTries                      *uint          `json:"max-tries"`
+	MaxUploadLimit                *ByteSize      `json:"max-upload-limit"`
+	MetalinkBaseURI               string         `json:"metalink-base-uri"`
+	MetalinkEnableUniqueProtocol  *bool          `json:"metalink-enable-unique-protocol"`
+	MetalinkLanguage              string         `json:"metalink-language"`
+	MetalinkLocation              string         `json:"metalink-location"`
+	MetalinkOS                    string         `json:"metalink-os"`
+	MetalinkPreferredProtocol     string         `json:"metalink-preferred-protocol"`
+	MetalinkVersion               string         `json:"metalink-version"`
+	MinSplitSize                  *ByteSize      `json:"min-split-size"`
+	NoFileAllocationLimit         *ByteSize      `json:"no-file-allocation-limit"`
+	NoNetrc                       *bool          `json:"no-netrc"`
+	NoProxy                       string         `json:"no-proxy"`
+	Out                           string         `json:"out"`
+	ParameterizedURI              *bool          `json:"parameterized-uri"`
+	Pause                         *bool          `json:"pause"`
+	PauseMetadata                 *bool          `json:"pause-metadata"`
+	PieceLength                   *ByteSize      `json:"piece-length"`
+	ProxyMethod                   string         `json:"proxy-method"`
+	RealtimeChunkChecksum         *bool          `json:"realtime-chunk-checksum"`
+	Referer                       string         `json:"referer"`
+	RemoteTime                    *bool          `json:"remote-time"`
+	RemoveControlFile             *bool          `json:"remove-control-file"`
+	RetryWait                     *time.Duration `json:"retry-wait"`
+	ReuseURI                      *bool          `json:"reuse-uri"`
+	RPCSaveUploadMetadata         *bool          `json:"rpc-save-upload-metadata"`
+	SeedRatio                     *float64       `json:"seed-ratio"`
+	SeedTime                      *time.Duration `json:"seed-time" unit:"minutes"` // Sent with a resolution of minutes
+	SelectFile                    string         `json:"select-file"`
+	Split                         *uint          `json:"split"`
+	SSHHostKeyMD                  string         `json:"ssh-host-key-md"`
+	StreamPieceSelector           string         `json:"stream-piece-selector"`
+	Timeout                       *time.Duration `json:"timeout"`
+	URISelector                   string         `json:"uri-selector"`
+	UseHead                       *bool          `json:"use-head"`
+	UserAgent                     string         `json:"user-agent"`
+
+	// Extra holds options which don't have a dedicated field.
+	// The keys must be the names aria2 uses for the options,
+	// package aria2proto provides constants for them.
+	// Fields of Options take precedence over values in Extra.
+	Extra map[string]string `json:"-"`
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	byteSizeType = reflect.TypeOf(ByteSize(0))
+)
+
+func durationUnit(field reflect.StructField) time.Duration {
+	if field.Tag.Get("unit") == "minutes" {
+		return time.Minute
+	}
+
+	return time.Second
+}
+
+func formatOption(field reflect.StructField, value reflect.Value) (string, bool) {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String(), value.String() != ""
+	case reflect.Slice:
+		return strings.Join(value.Interface().([]string), "\n"), value.Len() > 0
+	case reflect.Ptr:
+		if value.IsNil() {
+			return "", false
+		}
+	default:
+		return "", false
+	}
+
+	elem := value.Elem()
+	switch {
+	case elem.Type() == durationType:
+		d := time.Duration(elem.Int())
+		return strconv.FormatInt(int64(d/durationUnit(field)), 10), true
+	case elem.Kind() == reflect.Bool:
+		return strconv.FormatBool(elem.Bool()), true
+	case elem.Kind() == reflect.Float64:
+		return strconv.FormatFloat(elem.Float(), 'f', -1, 64), true
+	default:
+		return strconv.FormatUint(elem.Uint(), 10), true
+	}
+}
+
+func parseOption(field reflect.StructField, value reflect.Value, raw string) error {
+	switch value.Kind() {
+	case reflect.String:
+		value.SetString(raw)
+		return nil
+	case reflect.Slice:
+		value.Set(reflect.ValueOf(strings.Split(raw, "\n")))
+		return nil
+	}
+
+	elem := reflect.New(value.Type().Elem())
+	switch {
+	case elem.Elem().Type() == durationType:
+		// some durations, like seed-time, may be fractional
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+
+		elem.Elem().SetInt(int64(f * float64(durationUnit(field))))
+	case elem.Elem().Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+
+		elem.Elem().SetBool(b)
+	case elem.Elem().Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return err
+		}
+
+		elem.Elem().SetFloat(f)
+	case elem.Elem().Type() == byteSizeType:
+		size, err := ParseSize(raw)
+		if err != nil {
+			return err
+		}
+
+		elem.Elem().SetUint(uint64(size))
+	default:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return err
+		}
+
+		elem.Elem().SetUint(n)
+	}
+
+	value.Set(elem)
+	return nil
+}
+
+// SetMaxDownloadLimit sets the maximum download speed of a download in bytes/sec.
+// The limit is sent as a plain number of bytes.
+func (o *Options) SetMaxDownloadLimit(bytesPerSec ByteSize) {
+	o.MaxDownloadLimit = &bytesPerSec
+}
+
+// SetPaused sets the Pause option which makes aria2 add a new download in a paused state.
+// The download is put into the waiting queue without being started,
+// so its status is StatusPaused until it's unpaused using Unpause().
+// The option only applies when adding a download, it can't be changed using ChangeOptions().
+func (o *Options) SetPaused(paused bool) {
+	o.Pause = &paused
+}
+
+// SetSeedRatio makes aria2 stop seeding a torrent once the share ratio reaches ratio.
+// A ratio of 0 makes aria2 seed regardless of the ratio, see SetSeedTime() to limit the duration.
+func (o *Options) SetSeedRatio(ratio float64) {
+	o.SeedRatio = &ratio
+}
+
+// SetSeedTime makes aria2 stop seeding a torrent after seeding for d.
+// aria2 expects the time in minutes, so d is truncated to whole minutes.
+// A duration below one minute therefore disables seeding.
+func (o *Options) SetSeedTime(d time.Duration) {
+	o.SeedTime = &d
+}
+
+// checksumLengths maps the hash types supported by aria2 to the length of their hex digests.
+var checksumLengths = map[string]int{
+	"md5":     32,
+	"sha-1":   40,
+	"sha-224": 56,
+	"sha-256": 64,
+	"sha-384": 96,
+	"sha-512": 128,
+	"adler32": 8,
+}
+
+// SetChecksum sets the Checksum option which makes aria2 verify the downloaded file.
+// algo must be one of the hash types supported by aria2:
+// md5, sha-1, sha-224, sha-256, sha-384, sha-512 or adler32.
+// digest is the hex encoded digest of the file.
+// An error is returned if the hash type isn't supported or the digest doesn't match it,
+// in which case the option is left untouched.
+func (o *Options) SetChecksum(algo, digest string) error {
+	algo = strings.ToLower(algo)
+
+	length, ok := checksumLengths[algo]
+	if !ok {
+		return fmt.Errorf("unsupported checksum type %q", algo)
+	}
+
+	if _, err := hex.DecodeString(digest); err != nil || len(digest) != length {
+		return fmt.Errorf("invalid %s digest %q", algo, digest)
+	}
+
+	o.Checksum = algo + "=" + strings.ToLower(digest)
+	return nil
+}
+
+// SetIndexOut sets the path of the file with the given index of a BitTorrent download.
+// The index starts at 1, like the indices of SelectFile.
+// The path is relative to Dir.
+// Calling SetIndexOut again for the same index replaces its path.
+func (o *Options) SetIndexOut(index int, path string) {
+	prefix := strconv.Itoa(index) + "="
+	entry := prefix + path
+
+	for i, existing := range o.IndexOut {
+		if strings.HasPrefix(existing, prefix) {
+			o.IndexOut[i] = entry
+			return
+		}
+	}
+
+	o.IndexOut = append(o.IndexOut, entry)
+}
+
+// Clone returns a deep copy of the options.
+// Modifying the copy, including the values its pointer fields point to, doesn't affect o.
+func (o Options) Clone() Options {
+	return Options{}.Merge(o)
+}
+
+// Merge returns a copy of o with all fields which are set in override replaced by their value in override.
+// Values in Extra are merged key by key.
+// Neither o nor override are modified and the result doesn't share any memory with them.
+//
+// A field counts as set if it would be sent to aria2, see ToMap().
+func (o Options) Merge(override Options) Options {
+	merged := Options{}
+
+	v := reflect.ValueOf(&merged).Elem()
+	for _, source := range []Options{o, override} {
+		sourceValue := reflect.ValueOf(source)
+
+		for i := 0; i < v.NumField(); i++ {
+			if field := sourceValue.Field(i); field.Kind() != reflect.Map && !isZeroOption(field) {
+				v.Field(i).Set(copyOption(field))
+			}
+		}
+
+		for key, value := range source.Extra {
+			if merged.Extra == nil {
+				merged.Extra = make(map[string]string)
+			}
+
+			merged.Extra[key] = value
+		}
+	}
+
+	return merged
+}
+
+// isZeroOption reports whether the option field value isn't set.
+func isZeroOption(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String, reflect.Slice:
+		return value.Len() == 0
+	case reflect.Ptr:
+		return value.IsNil()
+	default:
+		return true
+	}
+}
+
+// copyOption returns a copy of the option field value which doesn't share memory with it.
+func copyOption(value reflect.Value) reflect.Value {
+	switch value.Kind() {
+	case reflect.Ptr:
+		elem := reflect.New(value.Type().Elem())
+		elem.Elem().Set(value.Elem())
+		return elem
+	case reflect.Slice:
+		return reflect.AppendSlice(reflect.MakeSlice(value.Type(), 0, value.Len()), value)
+	default:
+		return value
+	}
+}
+
+// ToMap renders the options into the string-keyed form aria2 expects.
+// Unset fields are omitted.
+// Options which can be given multiple times, like IndexOut,
+// have their values joined by newlines, which is how aria2 reports them.
+func (o Options) ToMap() map[string]string {
+	m := make(map[string]string, len(o.Extra))
+	for key, value := range o.Extra {
+		m[key] = value
+	}
+
+	v := reflect.ValueOf(o)
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("json")
+		if key == "" || key == "-" {
+			continue
+		}
+
+		if value, ok := formatOption(field, v.Field(i)); ok {
+			m[key] = value
+		}
+	}
+
+	return m
+}
+
+// MarshalJSON encodes the options as returned by the ToMap() method.
+// Options which can be given multiple times are encoded as a list of values
+// because aria2 doesn't split them when they are passed over RPC.
+func (o Options) MarshalJSON() ([]byte, error) {
+	m := make(map[string]interface{})
+	for key, value := range o.ToMap() {
+		m[key] = value
+	}
+
+	v := reflect.ValueOf(o)
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("json")
+		if value := v.Field(i); value.Kind() == reflect.Slice && value.Len() > 0 {
+			m[key] = value.Interface()
+		}
+	}
+
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON decodes the string-keyed options sent by aria2.
+// Options without a dedicated field are stored in Extra.
+// Values may also be lists of strings, which are joined by newlines.
+func (o *Options) UnmarshalJSON(data []byte) error {
+	var rawValues map[string]json.RawMessage
+	if err := json.Unmarshal(data, &rawValues); err != nil {
+		return err
+	}
+
+	m := make(map[string]string, len(rawValues))
+	for key, rawValue := range rawValues {
+		var value string
+		if err := json.Unmarshal(rawValue, &value); err == nil {
+			m[key] = value
+			continue
+		}
+
+		var values []string
+		if err := json.Unmarshal(rawValue, &values); err != nil {
+			return fmt.Errorf("invalid value %s for option %s: %v", rawValue, key, err)
+		}
+
+		m[key] = strings.Join(values, "\n")
+	}
+
+	options := Options{}
+
+	v := reflect.ValueOf(&options).Elem()
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("json")
+		raw, ok := m[key]
+		if key == "" || key == "-" || !ok {
+			continue
+		}
+
+		if err := parseOption(field, v.Field(i), raw); err != nil {
+			return fmt.Errorf("invalid value %q for option %s: %v", raw, key, err)
+		}
+
+		delete(m, key)
+	}
+
+	if len(m) > 0 {
+		options.Extra = m
+	}
+
+	*o = options
+	return nil
 }
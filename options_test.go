@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestOptionFormat(t *testing.T) {
@@ -18,9 +19,215 @@ func TestOptionFormat(t *testing.T) {
 	assert.NoError(t, json.Unmarshal(data, &options), "Couldn't unmarshal JSON")
 
 	assert.Equal(t, Options{
-		AllowOverwrite:         false,
-		AllowPieceLengthChange: false,
-		AlwaysResume:           true,
-		AsyncDNS:               true,
+		AllowOverwrite:         Bool(false),
+		AllowPieceLengthChange: Bool(false),
+		AlwaysResume:           Bool(true),
+		AsyncDNS:               Bool(true),
 	}, options)
 }
+
+func TestOptionTypedFormat(t *testing.T) {
+	data := []byte(`{
+		"split": "0",
+		"max-download-limit": "1048576",
+		"timeout": "60",
+		"seed-time": "1.5",
+		"seed-ratio": "1.5",
+		"select-file": "1-3,5",
+		"header": ["Accept: */*", "X-Token: abc"],
+		"some-new-option": "value"
+	}`)
+
+	var options Options
+	assert.NoError(t, json.Unmarshal(data, &options), "Couldn't unmarshal JSON")
+
+	assert.Equal(t, Options{
+		Split:            Uint(0),
+		MaxDownloadLimit: Size(MiB),
+		Timeout:          Duration(time.Minute),
+		SeedTime:         Duration(90 * time.Second),
+		SeedRatio:        Float(1.5),
+		SelectFile:       "1-3,5",
+		Header:           []string{"Accept: */*", "X-Token: abc"},
+		Extra:            map[string]string{"some-new-option": "value"},
+	}, options)
+
+	data, err := json.Marshal(Options{Header: options.Header})
+	assert.NoError(t, err, "Couldn't marshal JSON")
+	assert.JSONEq(t, `{"header": ["Accept: */*", "X-Token: abc"]}`, string(data))
+}
+
+func TestOptionToMap(t *testing.T) {
+	options := Options{
+		AllowOverwrite:   Bool(true),
+		AutoFileRenaming: Bool(false),
+		Dir:              "/downloads",
+		Split:            Uint(0),
+		MaxDownloadLimit: Size(500 * KiB),
+		ConnectTimeout:   Duration(90 * time.Second),
+		SeedTime:         Duration(2 * time.Hour),
+		Extra:            map[string]string{"dir": "/ignored", "some-new-option": "value"},
+	}
+
+	assert.Equal(t, map[string]string{
+		"allow-overwrite":    "true",
+		"auto-file-renaming": "false",
+		"dir":                "/downloads",
+		"split":              "0",
+		"max-download-limit": "512000",
+		"connect-timeout":    "90",
+		"seed-time":          "120",
+		"some-new-option":    "value",
+	}, options.ToMap())
+
+	data, err := json.Marshal(&options)
+	assert.NoError(t, err, "Couldn't marshal JSON")
+
+	var decoded Options
+	assert.NoError(t, json.Unmarshal(data, &decoded), "Couldn't unmarshal JSON")
+	assert.Equal(t, options.ToMap(), decoded.ToMap())
+}
+
+func TestOptionSetIndexOut(t *testing.T) {
+	var options Options
+	options.SetIndexOut(1, "a.mkv")
+	options.SetIndexOut(2, "b.mkv")
+	options.SetIndexOut(1, "renamed.mkv")
+	options.SetIndexOut(10, "c.mkv")
+
+	assert.Equal(t, []string{"1=renamed.mkv", "2=b.mkv", "10=c.mkv"}, options.IndexOut)
+	assert.Equal(t, "1=renamed.mkv\n2=b.mkv\n10=c.mkv", options.ToMap()["index-out"])
+
+	data, err := json.Marshal(&options)
+	assert.NoError(t, err, "Couldn't marshal JSON")
+	assert.JSONEq(t, `{"index-out": ["1=renamed.mkv", "2=b.mkv", "10=c.mkv"]}`, string(data))
+
+	var decoded Options
+	assert.NoError(t, json.Unmarshal(data, &decoded), "Couldn't unmarshal JSON")
+	assert.Equal(t, options, decoded)
+
+	decoded = Options{}
+	assert.NoError(t, json.Unmarshal([]byte(`{"index-out": "1=a.mkv\n2=b.mkv"}`), &decoded))
+	assert.Equal(t, []string{"1=a.mkv", "2=b.mkv"}, decoded.IndexOut)
+}
+
+func TestParseSize(t *testing.T) {
+	for raw, expected := range map[string]ByteSize{
+		"0":      0,
+		"1024":   KiB,
+		"500K":   500 * KiB,
+		"500k":   500 * KiB,
+		"1M":     MiB,
+		" 20m ":  20 * MiB,
+		"123456": 123456,
+	} {
+		size, err := ParseSize(raw)
+		if assert.NoError(t, err, raw) {
+			assert.Equal(t, expected, size, raw)
+		}
+	}
+
+	for _, raw := range []string{"", "K", "1G", "-1", "1.5M", "18446744073709551615M"} {
+		_, err := ParseSize(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestOptionSetMaxDownloadLimit(t *testing.T) {
+	var options Options
+	options.SetMaxDownloadLimit(2 * MiB)
+	assert.Equal(t, "2097152", options.ToMap()["max-download-limit"])
+
+	var decoded Options
+	assert.NoError(t, json.Unmarshal([]byte(`{"max-download-limit": "500K"}`), &decoded))
+	assert.Equal(t, Size(500*KiB), decoded.MaxDownloadLimit)
+}
+
+func TestOptionSetPaused(t *testing.T) {
+	var options Options
+	options.SetPaused(true)
+	assert.Equal(t, "true", options.ToMap()["pause"])
+
+	options.SetPaused(false)
+	assert.Equal(t, "false", options.ToMap()["pause"])
+}
+
+func TestOptionSetSeedLimits(t *testing.T) {
+	var options Options
+	options.SetSeedRatio(1.5)
+	options.SetSeedTime(90 * time.Minute)
+
+	m := options.ToMap()
+	assert.Equal(t, "1.5", m["seed-ratio"])
+	assert.Equal(t, "90", m["seed-time"], "seed-time should be sent in minutes")
+
+	options.SetSeedTime(90 * time.Second)
+	assert.Equal(t, "1", options.ToMap()["seed-time"])
+}
+
+func TestOptionSetChecksum(t *testing.T) {
+	var options Options
+
+	assert.NoError(t, options.SetChecksum("SHA-256", "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855"))
+	assert.Equal(t, "sha-256=e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855", options.Checksum)
+
+	assert.NoError(t, options.SetChecksum("md5", "d41d8cd98f00b204e9800998ecf8427e"))
+	assert.Equal(t, "md5=d41d8cd98f00b204e9800998ecf8427e", options.Checksum)
+
+	assert.Error(t, options.SetChecksum("sha256", "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"))
+	assert.Error(t, options.SetChecksum("sha-1", "d41d8cd98f00b204e9800998ecf8427e"))
+	assert.Error(t, options.SetChecksum("md5", "z41d8cd98f00b204e9800998ecf8427e"))
+	assert.Equal(t, "md5=d41d8cd98f00b204e9800998ecf8427e", options.Checksum)
+}
+
+func TestOptionClone(t *testing.T) {
+	options := Options{
+		Dir:      "/downloads",
+		Split:    Uint(4),
+		IndexOut: []string{"1=a.mkv"},
+		Extra:    map[string]string{"some-new-option": "value"},
+	}
+
+	clone := options.Clone()
+	assert.Equal(t, options, clone)
+
+	*clone.Split = 8
+	clone.IndexOut[0] = "1=b.mkv"
+	clone.Extra["some-new-option"] = "changed"
+
+	assert.Equal(t, uint(4), *options.Split)
+	assert.Equal(t, []string{"1=a.mkv"}, options.IndexOut)
+	assert.Equal(t, "value", options.Extra["some-new-option"])
+
+	assert.Equal(t, Options{}, Options{}.Clone())
+}
+
+func TestOptionMerge(t *testing.T) {
+	base := Options{
+		Dir:          "/downloads",
+		Split:        Uint(4),
+		AlwaysResume: Bool(true),
+		FTPPasv:      Bool(true),
+		Extra:        map[string]string{"a": "1", "b": "2"},
+	}
+
+	merged := base.Merge(Options{
+		Out:     "file.mkv",
+		Split:   Uint(8),
+		FTPPasv: Bool(false),
+		Extra:   map[string]string{"b": "3"},
+	})
+
+	assert.Equal(t, Options{
+		Dir:          "/downloads",
+		Out:          "file.mkv",
+		Split:        Uint(8),
+		AlwaysResume: Bool(true),
+		FTPPasv:      Bool(false),
+		Extra:        map[string]string{"a": "1", "b": "3"},
+	}, merged)
+
+	*merged.Split = 16
+	assert.Equal(t, uint(4), *base.Split)
+	assert.Equal(t, "2", base.Extra["b"])
+}
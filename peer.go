@@ -17,3 +17,10 @@ type Peer struct {
 	UploadSpeed   uint   `json:"uploadSpeed,string"`   // Upload speed (byte/sec) that this client uploads to the peer
 	Seeder        bool   `json:"seeder,string"`        // true if this peer is a seeder. Otherwise false
 }
+
+// Pieces decodes the BitField of the peer into a slice with an element for each piece.
+// An element is true if the peer has the corresponding piece.
+// numPieces is the number of pieces of the download (see Status.NumPieces).
+func (p Peer) Pieces(numPieces uint) ([]bool, error) {
+	return decodeBitField(p.BitField, numPieces)
+}
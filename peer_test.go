@@ -60,3 +60,11 @@ func TestPeerFormat(t *testing.T) {
 		UploadSpeed:   6890,
 	}, secondPeer)
 }
+
+func TestPeerPieces(t *testing.T) {
+	peer := Peer{BitField: "f0"}
+
+	pieces, err := peer.Pieces(6)
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{true, true, true, true, false, false}, pieces)
+}
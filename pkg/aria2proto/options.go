@@ -0,0 +1,212 @@
+package aria2proto
+
+// Names of the aria2 options.
+// See https://aria2.github.io/manual/en/html/aria2c.html#options for their meaning.
+const (
+	// Basic options
+	OptDir                    = "dir"
+	OptInputFile              = "input-file"
+	OptLog                    = "log"
+	OptMaxConcurrentDownloads = "max-concurrent-downloads"
+	OptCheckIntegrity         = "check-integrity"
+	OptContinue               = "continue"
+
+	// HTTP/FTP/SFTP options
+	OptAllProxy               = "all-proxy"
+	OptAllProxyPassword       = "all-proxy-passwd"
+	OptAllProxyUser           = "all-proxy-user"
+	OptChecksum               = "checksum"
+	OptConnectTimeout         = "connect-timeout"
+	OptDryRun                 = "dry-run"
+	OptLowestSpeedLimit       = "lowest-speed-limit"
+	OptMaxConnectionPerServer = "max-connection-per-server"
+	OptMaxFileNotFound        = "max-file-not-found"
+	OptMaxTries               = "max-tries"
+	OptMinSplitSize           = "min-split-size"
+	OptNetrcPath              = "netrc-path"
+	OptNoNetrc                = "no-netrc"
+	OptNoProxy                = "no-proxy"
+	OptOut                    = "out"
+	OptProxyMethod            = "proxy-method"
+	OptRemoteTime             = "remote-time"
+	OptReuseURI               = "reuse-uri"
+	OptRetryWait              = "retry-wait"
+	OptServerStatOf           = "server-stat-of"
+	OptServerStatIf           = "server-stat-if"
+	OptServerStatTimeout      = "server-stat-timeout"
+	OptSplit                  = "split"
+	OptStreamPieceSelector    = "stream-piece-selector"
+	OptTimeout                = "timeout"
+	OptURISelector            = "uri-selector"
+
+	// HTTP specific options
+	OptCACertificate        = "ca-certificate"
+	OptCertificate          = "certificate"
+	OptCheckCertificate     = "check-certificate"
+	OptHTTPAcceptGzip       = "http-accept-gzip"
+	OptHTTPAuthChallenge    = "http-auth-challenge"
+	OptHTTPNoCache          = "http-no-cache"
+	OptHTTPUser             = "http-user"
+	OptHTTPPasswd           = "http-passwd"
+	OptHTTPProxy            = "http-proxy"
+	OptHTTPProxyPasswd      = "http-proxy-passwd"
+	OptHTTPProxyUser        = "http-proxy-user"
+	OptHTTPSProxy           = "https-proxy"
+	OptHTTPSProxyPasswd     = "https-proxy-passwd"
+	OptHTTPSProxyUser       = "https-proxy-user"
+	OptPrivateKey           = "private-key"
+	OptReferer              = "referer"
+	OptEnableHTTPKeepAlive  = "enable-http-keep-alive"
+	OptEnableHTTPPipelining = "enable-http-pipelining"
+	OptHeader               = "header"
+	OptLoadCookies          = "load-cookies"
+	OptSaveCookies          = "save-cookies"
+	OptUseHead              = "use-head"
+	OptUserAgent            = "user-agent"
+
+	// FTP/SFTP specific options
+	OptFTPUser            = "ftp-user"
+	OptFTPPasswd          = "ftp-passwd"
+	OptFTPPasv            = "ftp-pasv"
+	OptFTPProxy           = "ftp-proxy"
+	OptFTPProxyPasswd     = "ftp-proxy-passwd"
+	OptFTPProxyUser       = "ftp-proxy-user"
+	OptFTPType            = "ftp-type"
+	OptFTPReuseConnection = "ftp-reuse-connection"
+	OptSSHHostKeyMD       = "ssh-host-key-md"
+
+	// BitTorrent/Metalink options
+	OptSelectFile = "select-file"
+
+	// BitTorrent specific options
+	OptBTDetachSeedOnly           = "bt-detach-seed-only"
+	OptBTEnableHookAfterHashCheck = "bt-enable-hook-after-hash-check"
+	OptBTEnableLpd                = "bt-enable-lpd"
+	OptBTExcludeTracker           = "bt-exclude-tracker"
+	OptBTExternalIP               = "bt-external-ip"
+	OptBTForceEncryption          = "bt-force-encryption"
+	OptBTHashCheckSeed            = "bt-hash-check-seed"
+	OptBTLoadSavedMetadata        = "bt-load-saved-metadata"
+	OptBTLpdInterface             = "bt-lpd-interface"
+	OptBTMaxOpenFiles             = "bt-max-open-files"
+	OptBTMaxPeers                 = "bt-max-peers"
+	OptBTMetadataOnly             = "bt-metadata-only"
+	OptBTMinCryptoLevel           = "bt-min-crypto-level"
+	OptBTPrioritizePiece          = "bt-prioritize-piece"
+	OptBTRemoveUnselectedFile     = "bt-remove-unselected-file"
+	OptBTRequireCrypto            = "bt-require-crypto"
+	OptBTRequestPeerSpeedLimit    = "bt-request-peer-speed-limit"
+	OptBTSaveMetadata             = "bt-save-metadata"
+	OptBTSeedUnverified           = "bt-seed-unverified"
+	OptBTStopTimeout              = "bt-stop-timeout"
+	OptBTTracker                  = "bt-tracker"
+	OptBTTrackerConnectTimeout    = "bt-tracker-connect-timeout"
+	OptBTTrackerInterval          = "bt-tracker-interval"
+	OptBTTrackerTimeout           = "bt-tracker-timeout"
+	OptDHTEntryPoint              = "dht-entry-point"
+	OptDHTEntryPoint6             = "dht-entry-point6"
+	OptDHTFilePath                = "dht-file-path"
+	OptDHTFilePath6               = "dht-file-path6"
+	OptDHTListenAddr6             = "dht-listen-addr6"
+	OptDHTListenPort              = "dht-listen-port"
+	OptDHTMessageTimeout          = "dht-message-timeout"
+	OptEnableDHT                  = "enable-dht"
+	OptEnableDHT6                 = "enable-dht6"
+	OptEnablePeerExchange         = "enable-peer-exchange"
+	OptFollowTorrent              = "follow-torrent"
+	OptIndexOut                   = "index-out"
+	OptListenPort                 = "listen-port"
+	OptMaxOverallUploadLimit      = "max-overall-upload-limit"
+	OptMaxUploadLimit             = "max-upload-limit"
+	OptPeerIDPrefix               = "peer-id-prefix"
+	OptPeerAgent                  = "peer-agent"
+	OptSeedRatio                  = "seed-ratio"
+	OptSeedTime                   = "seed-time"
+	OptTorrentFile                = "torrent-file"
+
+	// Metalink specific options
+	OptFollowMetalink               = "follow-metalink"
+	OptMetalinkBaseURI              = "metalink-base-uri"
+	OptMetalinkFile                 = "metalink-file"
+	OptMetalinkLanguage             = "metalink-language"
+	OptMetalinkLocation             = "metalink-location"
+	OptMetalinkOS                   = "metalink-os"
+	OptMetalinkVersion              = "metalink-version"
+	OptMetalinkPreferredProtocol    = "metalink-preferred-protocol"
+	OptMetalinkEnableUniqueProtocol = "metalink-enable-unique-protocol"
+
+	// RPC options
+	OptEnableRPC             = "enable-rpc"
+	OptPause                 = "pause"
+	OptPauseMetadata         = "pause-metadata"
+	OptRPCAllowOriginAll     = "rpc-allow-origin-all"
+	OptRPCCertificate        = "rpc-certificate"
+	OptRPCListenAll          = "rpc-listen-all"
+	OptRPCListenPort         = "rpc-listen-port"
+	OptRPCMaxRequestSize     = "rpc-max-request-size"
+	OptRPCPasswd             = "rpc-passwd"
+	OptRPCPrivateKey         = "rpc-private-key"
+	OptRPCSaveUploadMetadata = "rpc-save-upload-metadata"
+	OptRPCSecret             = "rpc-secret"
+	OptRPCSecure             = "rpc-secure"
+	OptRPCUser               = "rpc-user"
+
+	// Advanced options
+	OptAllowOverwrite                = "allow-overwrite"
+	OptAllowPieceLengthChange        = "allow-piece-length-change"
+	OptAlwaysResume                  = "always-resume"
+	OptAsyncDNS                      = "async-dns"
+	OptAsyncDNSServer                = "async-dns-server"
+	OptAutoFileRenaming              = "auto-file-renaming"
+	OptAutoSaveInterval              = "auto-save-interval"
+	OptConditionalGet                = "conditional-get"
+	OptConsoleLogLevel               = "console-log-level"
+	OptContentDispositionDefaultUTF8 = "content-disposition-default-utf8"
+	OptDeferredInput                 = "deferred-input"
+	OptDisableIPv6                   = "disable-ipv6"
+	OptDiskCache                     = "disk-cache"
+	OptDownloadResult                = "download-result"
+	OptDSCP                          = "dscp"
+	OptRlimitNofile                  = "rlimit-nofile"
+	OptEnableColor                   = "enable-color"
+	OptEnableMMap                    = "enable-mmap"
+	OptEventPoll                     = "event-poll"
+	OptFileAllocation                = "file-allocation"
+	OptForceSave                     = "force-save"
+	OptSaveNotFound                  = "save-not-found"
+	OptGID                           = "gid"
+	OptHashCheckOnly                 = "hash-check-only"
+	OptHumanReadable                 = "human-readable"
+	OptInterface                     = "interface"
+	OptKeepUnfinishedDownloadResult  = "keep-unfinished-download-result"
+	OptMaxDownloadResult             = "max-download-result"
+	OptMaxMMapLimit                  = "max-mmap-limit"
+	OptMaxResumeFailureTries         = "max-resume-failure-tries"
+	OptMinTLSVersion                 = "min-tls-version"
+	OptMultipleInterface             = "multiple-interface"
+	OptLogLevel                      = "log-level"
+	OptOnBTDownloadComplete          = "on-bt-download-complete"
+	OptOnDownloadComplete            = "on-download-complete"
+	OptOnDownloadError               = "on-download-error"
+	OptOnDownloadPause               = "on-download-pause"
+	OptOnDownloadStart               = "on-download-start"
+	OptOnDownloadStop                = "on-download-stop"
+	OptOptimizeConcurrentDownloads   = "optimize-concurrent-downloads"
+	OptPieceLength                   = "piece-length"
+	OptShowConsoleReadout            = "show-console-readout"
+	OptStderr                        = "stderr"
+	OptSummaryInterval               = "summary-interval"
+	OptForceSequential               = "force-sequential"
+	OptMaxOverallDownloadLimit       = "max-overall-download-limit"
+	OptMaxDownloadLimit              = "max-download-limit"
+	OptNoFileAllocationLimit         = "no-file-allocation-limit"
+	OptParameterizedURI              = "parameterized-uri"
+	OptRealtimeChunkChecksum         = "realtime-chunk-checksum"
+	OptRemoveControlFile             = "remove-control-file"
+	OptSaveSession                   = "save-session"
+	OptSaveSessionInterval           = "save-session-interval"
+	OptSocketRecvBufferSize          = "socket-recv-buffer-size"
+	OptStop                          = "stop"
+	OptStopWithProcess               = "stop-with-process"
+	OptTruncateConsoleReadout        = "truncate-console-readout"
+)
@@ -0,0 +1,151 @@
+package arigotest
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jae-jae/arigo"
+	"sync"
+)
+
+// HandlerFunc handles a call to a method of a Conn.
+// params are the parameters of the call as they would be decoded from JSON,
+// including the secret token if the client uses one.
+// The returned result is encoded to JSON and decoded into the reply of the call.
+// Returning an *arigo.RPCError simulates an error reported by aria2.
+type HandlerFunc func(params []interface{}) (result interface{}, err error)
+
+type notification struct {
+	method string
+	params json.RawMessage
+}
+
+// Conn is an in-memory arigo.RPCConn.
+// Calls are answered by the handlers registered for their methods and notifications
+// can be emitted using the Notify method.
+//
+// Use it with arigo.NewClientWithConn():
+//
+//	conn := arigotest.NewConn()
+//	client := arigo.NewClientWithConn(conn, "")
+//	go client.Run()
+type Conn struct {
+	mut      sync.RWMutex
+	handlers map[string]HandlerFunc
+
+	notifications chan notification
+	done          chan struct{}
+	closeOnce     sync.Once
+}
+
+// NewConn creates a new Conn without any handlers.
+func NewConn() *Conn {
+	return &Conn{
+		handlers:      make(map[string]HandlerFunc),
+		notifications: make(chan notification),
+		done:          make(chan struct{}),
+	}
+}
+
+// Handle registers the handler for the given method, replacing the previous one.
+// Calls to methods without a handler fail with an *arigo.RPCError.
+func (c *Conn) Handle(method string, handler HandlerFunc) {
+	c.mut.Lock()
+	defer c.mut.Unlock()
+
+	c.handlers[method] = handler
+}
+
+// HandleResult registers a handler for the given method which always returns result.
+func (c *Conn) HandleResult(method string, result interface{}) {
+	c.Handle(method, func([]interface{}) (interface{}, error) {
+		return result, nil
+	})
+}
+
+// Call calls the handler registered for method.
+func (c *Conn) Call(ctx context.Context, method string, params []interface{}, reply interface{}) error {
+	select {
+	case <-c.done:
+		return arigo.ErrClientClosed
+	default:
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	c.mut.RLock()
+	handler, ok := c.handlers[method]
+	c.mut.RUnlock()
+
+	if !ok {
+		return &arigo.RPCError{Code: 1, Message: "No such method: " + method}
+	}
+
+	// the params are passed through JSON so the handler sees the same values as aria2 would.
+	var decodedParams []interface{}
+	if err := roundTrip(params, &decodedParams); err != nil {
+		return err
+	}
+
+	result, err := handler(decodedParams)
+	if err != nil {
+		return err
+	}
+
+	if reply == nil {
+		return nil
+	}
+
+	return roundTrip(result, reply)
+}
+
+// Notify emits a notification for the downloads denoted by gids,
+// for example conn.Notify(aria2proto.OnDownloadComplete, gid).
+// It blocks until the client received the notification or the connection is closed.
+func (c *Conn) Notify(method string, gids ...string) {
+	events := make([]arigo.DownloadEvent, len(gids))
+	for i, gid := range gids {
+		events[i] = arigo.DownloadEvent{GID: gid}
+	}
+
+	params, _ := json.Marshal(events)
+
+	select {
+	case c.notifications <- notification{method: method, params: params}:
+	case <-c.done:
+	}
+}
+
+// Run passes the emitted notifications to handler until the connection is closed.
+func (c *Conn) Run(handler arigo.NotificationHandler) error {
+	for {
+		select {
+		case <-c.done:
+			return arigo.ErrClientClosed
+		case n := <-c.notifications:
+			if handler != nil {
+				handler(n.method, n.params)
+			}
+		}
+	}
+}
+
+// Close closes the connection.
+func (c *Conn) Close() error {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+
+	return nil
+}
+
+// roundTrip encodes src to JSON and decodes it into dst.
+func roundTrip(src interface{}, dst interface{}) error {
+	data, err := json.Marshal(src)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, dst)
+}
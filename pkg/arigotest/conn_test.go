@@ -0,0 +1,55 @@
+package arigotest
+
+import (
+	"github.com/jae-jae/arigo"
+	"github.com/jae-jae/arigo/pkg/aria2proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestConn(t *testing.T) {
+	conn := NewConn()
+	conn.HandleResult(aria2proto.TellStatus, arigo.Status{GID: "2089b05ecca3d829", Status: arigo.StatusActive})
+	conn.Handle(aria2proto.AddURI, func(params []interface{}) (interface{}, error) {
+		assert.Equal(t, []interface{}{"token:secret", []interface{}{"https://example.org/file"}}, params)
+		return "2089b05ecca3d829", nil
+	})
+
+	client := arigo.NewClientWithConn(conn, "secret")
+	go client.Run()
+	defer client.Close()
+
+	gid, err := client.AddURI(arigo.URIs("https://example.org/file"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "2089b05ecca3d829", gid.GID)
+
+	status, err := gid.TellStatus()
+	require.NoError(t, err)
+	assert.Equal(t, arigo.StatusActive, status.Status)
+
+	_, err = client.GetVersion()
+	assert.IsType(t, &arigo.RPCError{}, err)
+}
+
+func TestConnNotify(t *testing.T) {
+	conn := NewConn()
+	client := arigo.NewClientWithConn(conn, "")
+	go client.Run()
+	defer client.Close()
+
+	events := make(chan string, 1)
+	client.Subscribe(arigo.CompleteEvent, func(event *arigo.DownloadEvent) {
+		events <- event.GID
+	})
+
+	conn.Notify(aria2proto.OnDownloadComplete, "2089b05ecca3d829")
+
+	select {
+	case gid := <-events:
+		assert.Equal(t, "2089b05ecca3d829", gid)
+	case <-time.After(time.Second):
+		t.Fatal("notification wasn't delivered")
+	}
+}
@@ -0,0 +1,2 @@
+// Package arigotest provides fakes of aria2 which can be used to test code using arigo.
+package arigotest
@@ -0,0 +1,386 @@
+package arigotest
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gorilla/websocket"
+	"github.com/jae-jae/arigo"
+	"github.com/jae-jae/arigo/pkg/aria2proto"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+)
+
+// Server is a WebSocket server which speaks the JSON-RPC protocol of aria2.
+// It implements a small subset of the aria2 methods using an in-memory list of downloads:
+// aria2.addUri, aria2.tellStatus, aria2.tellActive, aria2.tellWaiting and aria2.remove.
+// The downloads don't make any progress on their own, use the Complete and Fail methods
+// to finish them.
+// Downloads added using the "pause" option are paused and put into the waiting queue
+// at the requested position.
+//
+// Notifications are sent to all connected clients when the state of a download changes.
+// Additional methods can be implemented, or the built-in ones replaced, using the Handle method.
+type Server struct {
+	*httptest.Server
+
+	// Secret is the secret token clients must send.
+	// It must not be changed while clients are connected.
+	Secret string
+
+	mut       sync.Mutex
+	handlers  map[string]HandlerFunc
+	downloads map[string]*arigo.Status
+	order     []string
+	queue     []string
+	lastGID   uint64
+	conns     map[*serverConn]struct{}
+}
+
+// serverConn is a WebSocket connection of a Server.
+type serverConn struct {
+	ws       *websocket.Conn
+	writeMut sync.Mutex
+}
+
+func (c *serverConn) write(v interface{}) error {
+	c.writeMut.Lock()
+	defer c.writeMut.Unlock()
+
+	return c.ws.WriteJSON(v)
+}
+
+// NewServer starts a new Server which requires clients to use the given secret token.
+// An empty secret disables authentication.
+// The server should be closed using the Close method once it's no longer needed.
+func NewServer(secret string) *Server {
+	s := &Server{
+		Secret:    secret,
+		handlers:  make(map[string]HandlerFunc),
+		downloads: make(map[string]*arigo.Status),
+		conns:     make(map[*serverConn]struct{}),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveWS))
+
+	return s
+}
+
+// Close closes the connections of all clients and shuts down the server.
+func (s *Server) Close() {
+	s.CloseConnections()
+	s.Server.Close()
+}
+
+// WSURL returns the WebSocket url of the server which can be passed to arigo.Dial().
+func (s *Server) WSURL() string {
+	return "ws" + strings.TrimPrefix(s.URL, "http")
+}
+
+// Handle registers the handler for the given method, replacing the built-in implementation if there is one.
+// The secret token is removed from the params before they are passed to the handler.
+func (s *Server) Handle(method string, handler HandlerFunc) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.handlers[method] = handler
+}
+
+// Download returns the status of the download denoted by gid.
+func (s *Server) Download(gid string) (arigo.Status, bool) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	status, ok := s.downloads[gid]
+	if !ok {
+		return arigo.Status{}, false
+	}
+
+	return *status, true
+}
+
+// Complete marks the download denoted by gid as completed and notifies the clients.
+func (s *Server) Complete(gid string) {
+	if s.setStatus(gid, arigo.StatusCompleted, 0, "") {
+		s.Notify(aria2proto.OnDownloadComplete, gid)
+	}
+}
+
+// Fail marks the download denoted by gid as failed with the given error and notifies the clients.
+func (s *Server) Fail(gid string, code arigo.ExitStatus, message string) {
+	if s.setStatus(gid, arigo.StatusError, code, message) {
+		s.Notify(aria2proto.OnDownloadError, gid)
+	}
+}
+
+func (s *Server) setStatus(gid string, status arigo.DownloadStatus, code arigo.ExitStatus, message string) bool {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	download, ok := s.downloads[gid]
+	if ok {
+		download.Status = status
+		download.ErrorCode = code
+		download.ErrorMessage = message
+		if status == arigo.StatusCompleted {
+			download.CompletedLength = download.TotalLength
+		}
+	}
+
+	return ok
+}
+
+// Notify sends a notification for the downloads denoted by gids to all connected clients.
+func (s *Server) Notify(method string, gids ...string) {
+	events := make([]arigo.DownloadEvent, len(gids))
+	for i, gid := range gids {
+		events[i] = arigo.DownloadEvent{GID: gid}
+	}
+
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": events}
+
+	for _, conn := range s.connections() {
+		_ = conn.write(msg)
+	}
+}
+
+// CloseConnections closes the connections of all clients without closing the server.
+// This can be used to test reconnecting.
+func (s *Server) CloseConnections() {
+	for _, conn := range s.connections() {
+		_ = conn.ws.Close()
+	}
+}
+
+func (s *Server) connections() []*serverConn {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	conns := make([]*serverConn, 0, len(s.conns))
+	for conn := range s.conns {
+		conns = append(conns, conn)
+	}
+
+	return conns
+}
+
+func (s *Server) serveWS(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	ws, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+
+	conn := &serverConn{ws: ws}
+
+	s.mut.Lock()
+	s.conns[conn] = struct{}{}
+	s.mut.Unlock()
+
+	defer func() {
+		s.mut.Lock()
+		delete(s.conns, conn)
+		s.mut.Unlock()
+
+		_ = ws.Close()
+	}()
+
+	for {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params []interface{}   `json:"params"`
+		}
+		if err := ws.ReadJSON(&req); err != nil {
+			return
+		}
+
+		resp := map[string]interface{}{"jsonrpc": "2.0", "id": req.ID}
+		if result, err := s.call(req.Method, req.Params); err != nil {
+			resp["error"] = toRPCError(err)
+		} else {
+			resp["result"] = result
+		}
+
+		if err := conn.write(resp); err != nil {
+			return
+		}
+	}
+}
+
+func toRPCError(err error) *arigo.RPCError {
+	if rpcErr, ok := err.(*arigo.RPCError); ok {
+		return rpcErr
+	}
+
+	return &arigo.RPCError{Code: 1, Message: err.Error()}
+}
+
+// call authenticates the call and passes it to its handler.
+func (s *Server) call(method string, params []interface{}) (interface{}, error) {
+	if !strings.HasPrefix(method, "system.") && s.Secret != "" {
+		if len(params) == 0 || params[0] != "token:"+s.Secret {
+			return nil, &arigo.RPCError{Code: 1, Message: "Unauthorized"}
+		}
+
+		params = params[1:]
+	}
+
+	s.mut.Lock()
+	handler, ok := s.handlers[method]
+	s.mut.Unlock()
+
+	if ok {
+		return handler(params)
+	}
+
+	switch method {
+	case aria2proto.AddURI:
+		return s.addURI(params)
+	case aria2proto.TellStatus:
+		return s.tellStatus(params)
+	case aria2proto.TellActive:
+		return s.tellActive()
+	case aria2proto.TellWaiting:
+		return s.tellWaiting(params)
+	case aria2proto.Remove:
+		return s.remove(params)
+	default:
+		return nil, &arigo.RPCError{Code: 1, Message: "No such method: " + method}
+	}
+}
+
+func (s *Server) addURI(params []interface{}) (interface{}, error) {
+	if len(params) == 0 {
+		return nil, &arigo.RPCError{Code: 1, Message: "URI is not provided."}
+	}
+
+	rawURIs, ok := params[0].([]interface{})
+	if !ok || len(rawURIs) == 0 {
+		return nil, &arigo.RPCError{Code: 1, Message: "URI is not provided."}
+	}
+
+	uris := make([]arigo.URI, len(rawURIs))
+	for i, uri := range rawURIs {
+		uris[i] = arigo.URI{URI: fmt.Sprint(uri), Status: arigo.URIWaiting}
+	}
+
+	paused := false
+	if len(params) > 1 {
+		options, _ := params[1].(map[string]interface{})
+		paused = options["pause"] == "true"
+	}
+
+	s.mut.Lock()
+	s.lastGID++
+	gid := fmt.Sprintf("%016x", s.lastGID)
+	s.downloads[gid] = &arigo.Status{
+		GID:    gid,
+		Status: arigo.StatusActive,
+		Files:  []arigo.File{{Index: 1, Selected: true, URIs: uris}},
+	}
+	s.order = append(s.order, gid)
+
+	if paused {
+		s.downloads[gid].Status = arigo.StatusPaused
+
+		position := len(s.queue)
+		if len(params) > 2 {
+			if p, ok := params[2].(float64); ok && p >= 0 && int(p) < position {
+				position = int(p)
+			}
+		}
+
+		s.queue = append(s.queue[:position], append([]string{gid}, s.queue[position:]...)...)
+	}
+	s.mut.Unlock()
+
+	if !paused {
+		// notify after the response was sent, like aria2 does
+		go s.Notify(aria2proto.OnDownloadStart, gid)
+	}
+
+	return gid, nil
+}
+
+func (s *Server) tellStatus(params []interface{}) (interface{}, error) {
+	gid, _ := gidParam(params)
+
+	status, ok := s.Download(gid)
+	if !ok {
+		return nil, &arigo.RPCError{Code: 1, Message: fmt.Sprintf("GID %s is not found", gid)}
+	}
+
+	return status, nil
+}
+
+func (s *Server) tellActive() (interface{}, error) {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	statuses := []arigo.Status{}
+	for _, gid := range s.order {
+		if status := s.downloads[gid]; status.Status == arigo.StatusActive {
+			statuses = append(statuses, *status)
+		}
+	}
+
+	return statuses, nil
+}
+
+func (s *Server) tellWaiting(params []interface{}) (interface{}, error) {
+	if len(params) < 2 {
+		return nil, &arigo.RPCError{Code: 1, Message: "Bad number of parameters"}
+	}
+
+	offset, _ := params[0].(float64)
+	num, _ := params[1].(float64)
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	statuses := []arigo.Status{}
+	for i := int(offset); i >= 0 && i < len(s.queue) && len(statuses) < int(num); i++ {
+		statuses = append(statuses, *s.downloads[s.queue[i]])
+	}
+
+	return statuses, nil
+}
+
+func (s *Server) remove(params []interface{}) (interface{}, error) {
+	gid, _ := gidParam(params)
+
+	s.mut.Lock()
+	download, ok := s.downloads[gid]
+	removable := ok && download.Status != arigo.StatusCompleted &&
+		download.Status != arigo.StatusError && download.Status != arigo.StatusRemoved
+	if removable {
+		download.Status = arigo.StatusRemoved
+
+		for i, queued := range s.queue {
+			if queued == gid {
+				s.queue = append(s.queue[:i], s.queue[i+1:]...)
+				break
+			}
+		}
+	}
+	s.mut.Unlock()
+
+	if !removable {
+		return nil, &arigo.RPCError{Code: 1, Message: fmt.Sprintf("Active Download not found for GID#%s", gid)}
+	}
+
+	go s.Notify(aria2proto.OnDownloadStop, gid)
+
+	return gid, nil
+}
+
+func gidParam(params []interface{}) (string, bool) {
+	if len(params) == 0 {
+		return "", false
+	}
+
+	gid, ok := params[0].(string)
+	return gid, ok
+}
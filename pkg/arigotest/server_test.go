@@ -0,0 +1,161 @@
+package arigotest
+
+import (
+	"github.com/jae-jae/arigo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestServer(t *testing.T) {
+	server := NewServer("secret")
+	defer server.Close()
+
+	client, err := arigo.Dial(server.WSURL(), "secret")
+	require.NoError(t, err)
+	defer client.Close()
+
+	gid, err := client.AddURI(arigo.URIs("https://example.org/file"), nil)
+	require.NoError(t, err)
+	assert.True(t, gid.IsValid())
+
+	active, err := client.TellActive()
+	require.NoError(t, err)
+	require.Len(t, active, 1)
+	assert.Equal(t, gid.GID, active[0].GID)
+
+	done := make(chan error)
+	go func() {
+		done <- gid.WaitForDownload()
+	}()
+
+	server.Complete(gid.GID)
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("download didn't complete")
+	}
+
+	assert.Error(t, gid.Remove(), "completed downloads can't be removed")
+}
+
+func TestServerUnauthorized(t *testing.T) {
+	server := NewServer("secret")
+	defer server.Close()
+
+	client, err := arigo.Dial(server.WSURL(), "wrong")
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.TellActive()
+	assert.Equal(t, &arigo.RPCError{Code: 1, Message: "Unauthorized"}, err)
+}
+
+func TestServerFail(t *testing.T) {
+	server := NewServer("")
+	defer server.Close()
+
+	client, err := arigo.Dial(server.WSURL(), "")
+	require.NoError(t, err)
+	defer client.Close()
+
+	gid, err := client.AddURI(arigo.URIs("https://example.org/file"), nil)
+	require.NoError(t, err)
+
+	server.Fail(gid.GID, arigo.ResourceNotFound, "Resource not found")
+
+	err = gid.WaitForDownload()
+	require.IsType(t, &arigo.DownloadError{}, err)
+	assert.Equal(t, arigo.ResourceNotFound, err.(*arigo.DownloadError).Code)
+}
+
+func TestServerReconnect(t *testing.T) {
+	server := NewServer("")
+	defer server.Close()
+
+	reconnected := make(chan arigo.ReconnectEvent, 1)
+	client, err := arigo.Dial(server.WSURL(), "",
+		arigo.WithReconnect(arigo.ReconnectPolicy{InitialDelay: 10 * time.Millisecond}),
+		arigo.WithReconnectListener(func(event arigo.ReconnectEvent) {
+			reconnected <- event
+		}),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	gid, err := client.AddURI(arigo.URIs("https://example.org/file"), nil)
+	require.NoError(t, err)
+
+	server.CloseConnections()
+
+	select {
+	case event := <-reconnected:
+		assert.NoError(t, event.Err)
+	case <-time.After(time.Second):
+		t.Fatal("client didn't reconnect")
+	}
+
+	status, err := gid.TellStatus()
+	require.NoError(t, err)
+	assert.Equal(t, arigo.StatusActive, status.Status)
+}
+
+func TestServerCallbacks(t *testing.T) {
+	server := NewServer("")
+	defer server.Close()
+
+	client, err := arigo.Dial(server.WSURL(), "")
+	require.NoError(t, err)
+	defer client.Close()
+
+	completed := make(chan arigo.Status, 1)
+	unsubscribe := client.OnComplete(func(gid arigo.GID, status arigo.Status) {
+		completed <- status
+	})
+
+	gid, err := client.AddURI(arigo.URIs("https://example.org/file"), nil)
+	require.NoError(t, err)
+
+	server.Complete(gid.GID)
+
+	select {
+	case status := <-completed:
+		assert.Equal(t, gid.GID, status.GID)
+		assert.Equal(t, arigo.StatusCompleted, status.Status)
+	case <-time.After(time.Second):
+		t.Fatal("callback wasn't called")
+	}
+
+	assert.True(t, unsubscribe())
+}
+
+func TestServerAddAtPosition(t *testing.T) {
+	server := NewServer("")
+	defer server.Close()
+
+	client, err := arigo.Dial(server.WSURL(), "")
+	require.NoError(t, err)
+	defer client.Close()
+
+	paused := &arigo.Options{}
+	paused.SetPaused(true)
+
+	first, err := client.AddURI(arigo.URIs("https://example.org/first"), paused)
+	require.NoError(t, err)
+
+	status, err := first.TellStatus()
+	require.NoError(t, err)
+	assert.Equal(t, arigo.StatusPaused, status.Status)
+
+	second, err := client.AddURIAtPosition(arigo.URIs("https://example.org/second"), 0, paused)
+	require.NoError(t, err)
+
+	waiting, err := client.TellWaiting(0, 10)
+	require.NoError(t, err)
+	require.Len(t, waiting, 2)
+	assert.Equal(t, second.GID, waiting[0].GID)
+	assert.Equal(t, first.GID, waiting[1].GID)
+}
@@ -0,0 +1,135 @@
+package arigo
+
+import (
+	"sync"
+	"time"
+)
+
+// ReconnectPolicy determines how a lost connection is re-established.
+//
+// The first attempt is made after InitialDelay.
+// After every failed attempt the delay is multiplied by Multiplier
+// but it never exceeds MaxDelay.
+type ReconnectPolicy struct {
+	MaxAttempts  uint          // Maximum number of attempts. 0 means there's no limit.
+	InitialDelay time.Duration // Delay before the first attempt
+	MaxDelay     time.Duration // Upper limit for the delay. 0 means there's no limit.
+	Multiplier   float64       // Factor the delay is multiplied with after each attempt. Values below 1 are treated as 1.
+}
+
+// DefaultReconnectPolicy is a reasonable ReconnectPolicy which retries forever.
+var DefaultReconnectPolicy = ReconnectPolicy{
+	InitialDelay: time.Second,
+	MaxDelay:     30 * time.Second,
+	Multiplier:   2,
+}
+
+// nextDelay returns the delay to wait for after the given delay.
+func (p ReconnectPolicy) nextDelay(delay time.Duration) time.Duration {
+	if p.Multiplier > 1 {
+		delay = time.Duration(float64(delay) * p.Multiplier)
+	}
+
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+
+	return delay
+}
+
+// ReconnectEvent describes an attempt to re-establish a lost connection.
+type ReconnectEvent struct {
+	Attempt uint  // Number of the attempt, starting at 1
+	Err     error // Error of the attempt. nil if the connection was re-established.
+}
+
+// ReconnectListener represents a function which is called for every
+// attempt to re-establish a lost connection.
+type ReconnectListener func(event ReconnectEvent)
+
+// ResumeEvent is delivered on the Resumed channel of a Subscription
+// after a lost connection was re-established.
+// Notifications sent by aria2 while the client was disconnected are lost.
+// If WithResumeReconcile() is used, the event contains the state of the downloads
+// after reconnecting which can be used to detect the changes that were missed.
+type ResumeEvent struct {
+	Active  []Status // Active downloads. Only set if WithResumeReconcile() is used.
+	Stopped []Status // Recently stopped downloads, most recent first. Only set if WithResumeReconcile() is used.
+	Err     error    // Error of the calls made to reconcile the state, if any
+}
+
+// filter returns a copy of the event which only contains the statuses of the download with the given gid.
+func (e *ResumeEvent) filter(gid string) *ResumeEvent {
+	filterStatuses := func(statuses []Status) []Status {
+		if statuses == nil {
+			return nil
+		}
+
+		filtered := []Status{}
+		for _, status := range statuses {
+			if status.GID == gid {
+				filtered = append(filtered, status)
+			}
+		}
+
+		return filtered
+	}
+
+	return &ResumeEvent{
+		Active:  filterStatuses(e.Active),
+		Stopped: filterStatuses(e.Stopped),
+		Err:     e.Err,
+	}
+}
+
+// resumeTarget dispatches ResumeEvents to the subscriptions of a client.
+type resumeTarget struct {
+	listeners map[uint64]func(event *ResumeEvent)
+	currentID uint64
+	mut       sync.RWMutex
+}
+
+// subscribe registers listener and returns a function which unregisters it again.
+// A dispatch which is already running may still call the listener after it was unregistered.
+func (t *resumeTarget) subscribe(listener func(event *ResumeEvent)) func() {
+	t.mut.Lock()
+	defer t.mut.Unlock()
+
+	id := t.currentID
+	t.currentID++
+
+	if t.listeners == nil {
+		t.listeners = make(map[uint64]func(event *ResumeEvent))
+	}
+
+	t.listeners[id] = listener
+
+	return func() {
+		t.mut.Lock()
+		defer t.mut.Unlock()
+
+		delete(t.listeners, id)
+	}
+}
+
+// dispatch calls all listeners with event and waits for them to return.
+func (t *resumeTarget) dispatch(event *ResumeEvent) {
+	t.mut.RLock()
+	listeners := make([]func(event *ResumeEvent), 0, len(t.listeners))
+	for _, listener := range t.listeners {
+		listeners = append(listeners, listener)
+	}
+	t.mut.RUnlock()
+
+	var wg sync.WaitGroup
+
+	wg.Add(len(listeners))
+	for _, listener := range listeners {
+		go func(listener func(event *ResumeEvent)) {
+			listener(event)
+			wg.Done()
+		}(listener)
+	}
+
+	wg.Wait()
+}
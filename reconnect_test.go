@@ -0,0 +1,233 @@
+package arigo
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestReconnectPolicyDelay(t *testing.T) {
+	policy := ReconnectPolicy{InitialDelay: time.Second, MaxDelay: 5 * time.Second, Multiplier: 2}
+
+	assert.Equal(t, 2*time.Second, policy.nextDelay(time.Second))
+	assert.Equal(t, 4*time.Second, policy.nextDelay(2*time.Second))
+	assert.Equal(t, 5*time.Second, policy.nextDelay(4*time.Second))
+
+	policy = ReconnectPolicy{InitialDelay: time.Second}
+	assert.Equal(t, time.Second, policy.nextDelay(time.Second))
+}
+
+func TestClientReconnect(t *testing.T) {
+	var connections int32
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		// drop the first connection right away
+		if atomic.AddInt32(&connections, 1) == 1 {
+			return
+		}
+
+		for {
+			var req map[string]interface{}
+			if err := ws.ReadJSON(&req); err != nil {
+				return
+			}
+
+			_ = ws.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": "OK"})
+		}
+	}))
+	defer server.Close()
+
+	events := make(chan ReconnectEvent, 1)
+
+	client, err := Dial("ws"+strings.TrimPrefix(server.URL, "http"), "",
+		WithReconnect(ReconnectPolicy{InitialDelay: 10 * time.Millisecond, MaxAttempts: 3}),
+		WithReconnectListener(func(event ReconnectEvent) {
+			events <- event
+		}),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	select {
+	case event := <-events:
+		assert.Equal(t, ReconnectEvent{Attempt: 1}, event)
+	case <-time.After(time.Second):
+		t.Fatal("client didn't reconnect")
+	}
+
+	assert.NoError(t, client.SaveSession())
+}
+
+func TestClientResumeReconcile(t *testing.T) {
+	var connections int32
+	drop := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		// drop the first connection once the subscription exists
+		if atomic.AddInt32(&connections, 1) == 1 {
+			<-drop
+			return
+		}
+
+		for {
+			var req map[string]interface{}
+			if err := ws.ReadJSON(&req); err != nil {
+				return
+			}
+
+			var result interface{}
+			switch req["method"] {
+			case "aria2.tellActive":
+				result = []map[string]string{{"gid": "1", "status": "active"}}
+			case "aria2.tellStopped":
+				result = []map[string]string{{"gid": "2", "status": "complete"}}
+			}
+
+			_ = ws.WriteJSON(map[string]interface{}{"jsonrpc": "2.0", "id": req["id"], "result": result})
+		}
+	}))
+	defer server.Close()
+
+	client, err := Dial("ws"+strings.TrimPrefix(server.URL, "http"), "",
+		WithReconnect(ReconnectPolicy{InitialDelay: 10 * time.Millisecond, MaxAttempts: 3}),
+		WithResumeReconcile(10),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	sub := client.NewSubscription(1)
+	defer sub.Close()
+
+	close(drop)
+
+	select {
+	case event := <-sub.Resumed:
+		assert.Equal(t, &ResumeEvent{
+			Active:  []Status{{GID: "1", Status: StatusActive}},
+			Stopped: []Status{{GID: "2", Status: StatusCompleted}},
+		}, event)
+	case <-time.After(time.Second):
+		t.Fatal("subscription wasn't resumed")
+	}
+}
+
+func TestClientResumeUndrained(t *testing.T) {
+	var connections int32
+	drop := make(chan struct{})
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		if atomic.AddInt32(&connections, 1) == 1 {
+			<-drop
+			return
+		}
+
+		var req map[string]interface{}
+		_ = ws.ReadJSON(&req)
+	}))
+	defer server.Close()
+
+	client, err := Dial("ws"+strings.TrimPrefix(server.URL, "http"), "",
+		WithReconnect(ReconnectPolicy{InitialDelay: 10 * time.Millisecond, MaxAttempts: 3}),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	// never received from
+	undrained := client.NewSubscription(0)
+	defer undrained.Close()
+
+	close(drop)
+
+	deadline := time.Now().Add(time.Second)
+	for len(undrained.Resumed) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscription wasn't resumed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	created := make(chan *Subscription)
+	go func() {
+		created <- client.NewSubscription(0)
+	}()
+
+	select {
+	case sub := <-created:
+		sub.Close()
+	case <-time.After(time.Second):
+		t.Fatal("creating a subscription blocked")
+	}
+}
+
+func TestClientStateChanges(t *testing.T) {
+	var connections int32
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		// drop the first connection right away
+		if atomic.AddInt32(&connections, 1) == 1 {
+			return
+		}
+
+		var req map[string]interface{}
+		_ = ws.ReadJSON(&req)
+	}))
+	defer server.Close()
+
+	client, err := Dial("ws"+strings.TrimPrefix(server.URL, "http"), "",
+		WithReconnect(ReconnectPolicy{InitialDelay: 10 * time.Millisecond, MaxAttempts: 3}),
+	)
+	require.NoError(t, err)
+
+	expected := []ConnState{StateConnected, StateReconnecting, StateConnected}
+	for _, state := range expected {
+		select {
+		case received := <-client.StateChanges():
+			assert.Equal(t, state, received)
+		case <-time.After(time.Second):
+			t.Fatalf("didn't receive state %s", state)
+		}
+	}
+
+	assert.Equal(t, StateConnected, client.State())
+
+	require.NoError(t, client.Close())
+	assert.Equal(t, StateClosed, client.State())
+	assert.Equal(t, StateClosed, <-client.StateChanges())
+
+	_, ok := <-client.StateChanges()
+	assert.False(t, ok, "channel should be closed")
+}
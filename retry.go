@@ -0,0 +1,53 @@
+package arigo
+
+import (
+	"github.com/jae-jae/arigo/pkg/aria2proto"
+	"net"
+	"time"
+)
+
+// RetryPolicy determines how calls which failed because of a transient
+// transport error are retried.
+//
+// Only methods which don't change the state of aria2, such as TellStatus() or GetFiles(),
+// are retried. Methods like AddURI() are never retried to avoid duplicate downloads.
+type RetryPolicy struct {
+	MaxAttempts uint          // Maximum number of attempts, including the first one
+	Delay       time.Duration // Delay between two attempts
+}
+
+// DefaultRetryPolicy is a reasonable RetryPolicy for short network interruptions.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	Delay:       500 * time.Millisecond,
+}
+
+// idempotentMethods contains the methods which can safely be retried.
+var idempotentMethods = map[string]bool{
+	aria2proto.TellStatus:        true,
+	aria2proto.TellActive:        true,
+	aria2proto.TellWaiting:       true,
+	aria2proto.TellStopped:       true,
+	aria2proto.GetURIs:           true,
+	aria2proto.GetFiles:          true,
+	aria2proto.GetPeers:          true,
+	aria2proto.GetServers:        true,
+	aria2proto.GetOptions:        true,
+	aria2proto.GetGlobalOptions:  true,
+	aria2proto.GetGlobalStats:    true,
+	aria2proto.GetVersion:        true,
+	aria2proto.GetSessionInfo:    true,
+	aria2proto.ListMethods:       true,
+	aria2proto.ListNotifications: true,
+}
+
+// isTransientError reports whether err was caused by the transport
+// and the call might succeed when it's retried.
+func isTransientError(err error) bool {
+	if err == ErrConnectionLost {
+		return true
+	}
+
+	_, ok := err.(net.Error)
+	return ok
+}
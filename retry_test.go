@@ -0,0 +1,76 @@
+package arigo
+
+import (
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// startFlakyServer starts a server which drops the connection when the first request is received.
+func startFlakyServer(t *testing.T) string {
+	var connections int32
+	upgrader := websocket.Upgrader{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer ws.Close()
+
+		first := atomic.AddInt32(&connections, 1) == 1
+
+		for {
+			var req map[string]interface{}
+			if err := ws.ReadJSON(&req); err != nil || first {
+				return
+			}
+
+			_ = ws.WriteJSON(map[string]interface{}{
+				"jsonrpc": "2.0",
+				"id":      req["id"],
+				"result":  map[string]interface{}{"gid": "2089b05ecca3d829", "status": "active"},
+			})
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestRetry(t *testing.T) {
+	client, err := Dial(startFlakyServer(t), "",
+		WithReconnect(ReconnectPolicy{InitialDelay: 10 * time.Millisecond}),
+		WithRetry(RetryPolicy{MaxAttempts: 3, Delay: 50 * time.Millisecond}),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	status, err := client.TellStatus("2089b05ecca3d829")
+	require.NoError(t, err)
+	assert.Equal(t, StatusActive, status.Status)
+}
+
+func TestRetryNotIdempotent(t *testing.T) {
+	client, err := Dial(startFlakyServer(t), "",
+		WithReconnect(ReconnectPolicy{InitialDelay: 10 * time.Millisecond}),
+		WithRetry(RetryPolicy{MaxAttempts: 3, Delay: 50 * time.Millisecond}),
+	)
+	require.NoError(t, err)
+	defer client.Close()
+
+	_, err = client.AddURI(URIs("https://example.org/file"), nil)
+	assert.Equal(t, ErrConnectionLost, err, "AddURI must not be retried")
+}
+
+func TestIsTransientError(t *testing.T) {
+	assert.True(t, isTransientError(ErrConnectionLost))
+	assert.False(t, isTransientError(ErrClientClosed))
+	assert.False(t, isTransientError(&RPCError{Code: 1, Message: "GID 2089b05ecca3d829 is not found"}))
+}
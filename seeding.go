@@ -0,0 +1,74 @@
+package arigo
+
+import (
+	"sync"
+	"time"
+)
+
+// SeedTracker records when BitTorrent downloads started seeding.
+// aria2 doesn't report for how long a download has been seeding,
+// the tracker derives it from the BTCompleteEvent of the download instead.
+// Downloads which started seeding before the tracker was created aren't tracked.
+type SeedTracker struct {
+	mut         sync.RWMutex
+	started     map[string]time.Time
+	unsubscribe []UnsubscribeFunc
+}
+
+// NewSeedTracker creates a SeedTracker which tracks the downloads of target.
+// target is usually a Client.
+// The tracker should be closed using its Close method once it's no longer needed.
+func NewSeedTracker(target EventSubscriber) *SeedTracker {
+	t := &SeedTracker{started: make(map[string]time.Time)}
+
+	t.unsubscribe = []UnsubscribeFunc{
+		target.Subscribe(BTCompleteEvent, func(event *DownloadEvent) {
+			t.mut.Lock()
+			defer t.mut.Unlock()
+
+			if _, ok := t.started[event.GID]; !ok {
+				t.started[event.GID] = time.Now()
+			}
+		}),
+	}
+
+	// seeding ends when the download stops
+	for _, evtType := range []EventType{StopEvent, CompleteEvent, ErrorEvent} {
+		t.unsubscribe = append(t.unsubscribe, target.Subscribe(evtType, func(event *DownloadEvent) {
+			t.mut.Lock()
+			defer t.mut.Unlock()
+
+			delete(t.started, event.GID)
+		}))
+	}
+
+	return t
+}
+
+// SeedingSince returns the time the download denoted by gid started seeding.
+// The second return value is false if the download isn't seeding or isn't tracked.
+func (t *SeedTracker) SeedingSince(gid string) (time.Time, bool) {
+	t.mut.RLock()
+	defer t.mut.RUnlock()
+
+	started, ok := t.started[gid]
+	return started, ok
+}
+
+// SeedingDuration returns for how long the download denoted by gid has been seeding.
+// The second return value is false if the download isn't seeding or isn't tracked.
+func (t *SeedTracker) SeedingDuration(gid string) (time.Duration, bool) {
+	started, ok := t.SeedingSince(gid)
+	if !ok {
+		return 0, false
+	}
+
+	return time.Since(started), true
+}
+
+// Close stops tracking downloads.
+func (t *SeedTracker) Close() {
+	for _, unsubscribe := range t.unsubscribe {
+		unsubscribe()
+	}
+}
@@ -0,0 +1,34 @@
+package arigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestSeedTracker(t *testing.T) {
+	var evtTarget eventTarget
+
+	tracker := NewSeedTracker(&evtTarget)
+
+	_, ok := tracker.SeedingDuration("1")
+	assert.False(t, ok)
+
+	before := time.Now()
+	evtTarget.Dispatch(BTCompleteEvent, &DownloadEvent{GID: "1"})
+
+	since, ok := tracker.SeedingSince("1")
+	assert.True(t, ok)
+	assert.False(t, since.Before(before))
+
+	duration, ok := tracker.SeedingDuration("1")
+	assert.True(t, ok)
+	assert.True(t, duration >= 0)
+
+	evtTarget.Dispatch(CompleteEvent, &DownloadEvent{GID: "1"})
+	_, ok = tracker.SeedingSince("1")
+	assert.False(t, ok)
+
+	tracker.Close()
+	assert.Empty(t, evtTarget.listenerMap)
+}
@@ -0,0 +1,78 @@
+package arigo
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SelectFiles formats the given file indices for the SelectFile option.
+// Indices start at 1, like the Index of a File.
+// Duplicates are removed and consecutive indices are collapsed into ranges,
+// for example SelectFiles(5, 1, 2, 3) returns "1-3,5".
+func SelectFiles(indices ...int) string {
+	sorted := append([]int(nil), indices...)
+	sort.Ints(sorted)
+
+	var parts []string
+	for i := 0; i < len(sorted); {
+		start, end := sorted[i], sorted[i]
+
+		for i++; i < len(sorted) && sorted[i] <= end+1; i++ {
+			end = sorted[i]
+		}
+
+		if start == end {
+			parts = append(parts, strconv.Itoa(start))
+		} else {
+			parts = append(parts, strconv.Itoa(start)+"-"+strconv.Itoa(end))
+		}
+	}
+
+	return strings.Join(parts, ",")
+}
+
+// ParseSelectFile parses the value of the SelectFile option into
+// the file indices it contains, in ascending order and without duplicates.
+// An empty value results in an empty slice.
+func ParseSelectFile(selectFile string) ([]int, error) {
+	seen := make(map[int]bool)
+	indices := []int{}
+
+	add := func(index int) {
+		if !seen[index] {
+			seen[index] = true
+			indices = append(indices, index)
+		}
+	}
+
+	for _, part := range strings.Split(selectFile, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		bounds := strings.SplitN(part, "-", 2)
+
+		start, err := strconv.Atoi(bounds[0])
+		if err != nil || start < 1 {
+			return nil, fmt.Errorf("invalid file index %q in %q", bounds[0], selectFile)
+		}
+
+		end := start
+		if len(bounds) == 2 {
+			end, err = strconv.Atoi(bounds[1])
+			if err != nil || end < start {
+				return nil, fmt.Errorf("invalid file range %q in %q", part, selectFile)
+			}
+		}
+
+		for index := start; index <= end; index++ {
+			add(index)
+		}
+	}
+
+	sort.Ints(indices)
+	return indices, nil
+}
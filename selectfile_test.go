@@ -0,0 +1,37 @@
+package arigo
+
+import (
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestSelectFiles(t *testing.T) {
+	assert.Equal(t, "", SelectFiles())
+	assert.Equal(t, "4", SelectFiles(4))
+	assert.Equal(t, "1-3,5", SelectFiles(5, 1, 2, 3))
+	assert.Equal(t, "1-2,4,6-8", SelectFiles(8, 7, 6, 4, 2, 1, 2))
+}
+
+func TestParseSelectFile(t *testing.T) {
+	indices, err := ParseSelectFile("1-3,5")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 3, 5}, indices)
+
+	indices, err = ParseSelectFile("6-8, 4,2,1-2")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2, 4, 6, 7, 8}, indices)
+
+	indices, err = ParseSelectFile("")
+	require.NoError(t, err)
+	assert.Empty(t, indices)
+
+	for _, invalid := range []string{"a", "0", "3-1", "1-b", "-2"} {
+		_, err = ParseSelectFile(invalid)
+		assert.Error(t, err, invalid)
+	}
+
+	indices, err = ParseSelectFile(SelectFiles(10, 3, 4, 5, 1))
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3, 4, 5, 10}, indices)
+}
@@ -12,5 +12,17 @@ type Stats struct {
 	NumStopped uint `json:"numStopped,string"`
 
 	// The number of stopped downloads in the current session and not capped by the MaxDownloadResult option.
+	// Older versions of aria2 don't report this value, in which case it is 0.
 	NumStoppedTotal uint `json:"numStoppedTotal,string"`
 }
+
+// HiddenStoppedCount returns the number of stopped downloads which were trimmed
+// from the list of stopped downloads because of the MaxDownloadResult option.
+// It returns 0 if aria2 doesn't report NumStoppedTotal.
+func (s Stats) HiddenStoppedCount() uint {
+	if s.NumStoppedTotal < s.NumStopped {
+		return 0
+	}
+
+	return s.NumStoppedTotal - s.NumStopped
+}
@@ -26,3 +26,30 @@ func TestStatsFormat(t *testing.T) {
 		UploadSpeed:   0,
 	}, stats)
 }
+
+func TestStatsStoppedTotalFormat(t *testing.T) {
+	data := []byte(`{
+		"downloadSpeed": "0",
+		"numActive": "0",
+		"numStopped": "1000",
+		"numStoppedTotal": "1234",
+		"numWaiting": "3",
+		"uploadSpeed": "512"
+	}`)
+
+	var stats Stats
+	assert.NoError(t, json.Unmarshal(data, &stats), "Couldn't unmarshal JSON")
+
+	assert.Equal(t, Stats{
+		NumStopped:      1000,
+		NumStoppedTotal: 1234,
+		NumWaiting:      3,
+		UploadSpeed:     512,
+	}, stats)
+}
+
+func TestStatsHiddenStoppedCount(t *testing.T) {
+	assert.EqualValues(t, 7, Stats{NumStopped: 1000, NumStoppedTotal: 1007}.HiddenStoppedCount())
+	assert.EqualValues(t, 0, Stats{NumStopped: 5, NumStoppedTotal: 5}.HiddenStoppedCount())
+	assert.EqualValues(t, 0, Stats{NumStopped: 5}.HiddenStoppedCount())
+}
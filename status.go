@@ -1,7 +1,13 @@
 package arigo
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -18,7 +24,7 @@ const (
 	// StatusError represents downloads that were stopped because of error
 	StatusError DownloadStatus = "error"
 	// StatusCompleted represents stopped and completed downloads
-	StatusCompleted DownloadStatus = "completed"
+	StatusCompleted DownloadStatus = "complete"
 	// StatusRemoved represents the downloads removed by user
 	StatusRemoved DownloadStatus = "removed"
 )
@@ -81,6 +87,427 @@ type Status struct {
 	VerifyIntegrityPending bool `json:"verifyIntegrityPending,string"`
 }
 
+var statusDecoder = newLenientDecoder(reflect.TypeOf(Status{}))
+
+// UnmarshalJSON decodes the status sent by aria2.
+// Numeric fields which aria2 sent as an empty string are left at zero.
+func (s *Status) UnmarshalJSON(data []byte) error {
+	return statusDecoder.decode(data, reflect.ValueOf(s).Elem())
+}
+
+// lenientDecoder decodes JSON objects into a struct type in a single pass.
+// Numeric and boolean fields which are tagged with the string option
+// may contain an empty string, in which case they are left untouched.
+// encoding/json can't decode these, aria2 however sends them for downloads
+// which haven't been started yet.
+//
+// The object is decoded into a struct whose fields point to the fields of the target struct.
+// The pointers to fields which are tagged with the string option use types which accept empty strings.
+type lenientDecoder struct {
+	fields   []int        // Indices of the fields of the target struct
+	pointers reflect.Type // Struct with a pointer for every field in fields
+}
+
+// lenientTypes maps the kinds of fields tagged with the string option to the types used to decode them.
+var lenientTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:  reflect.TypeOf(stringBool(false)),
+	reflect.Int:   reflect.TypeOf(stringInt(0)),
+	reflect.Uint:  reflect.TypeOf(stringUint(0)),
+	reflect.Uint8: reflect.TypeOf(stringUint8(0)),
+}
+
+func newLenientDecoder(t reflect.Type) lenientDecoder {
+	var d lenientDecoder
+	var pointers []reflect.StructField
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+
+		fieldType := field.Type
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		if lenient, ok := lenientTypes[fieldType.Kind()]; ok && len(tag) > 1 && tag[1] == "string" {
+			fieldType = lenient
+			field.Tag = reflect.StructTag(`json:"` + tag[0] + `"`)
+		}
+
+		d.fields = append(d.fields, i)
+		pointers = append(pointers, reflect.StructField{
+			Name: field.Name,
+			Type: reflect.PtrTo(fieldType),
+			Tag:  field.Tag,
+		})
+	}
+
+	d.pointers = reflect.StructOf(pointers)
+	return d
+}
+
+// decode decodes data into the struct v.
+func (d lenientDecoder) decode(data []byte, v reflect.Value) error {
+	pointers := reflect.New(d.pointers).Elem()
+	for i, field := range d.fields {
+		pointer := pointers.Field(i)
+		pointer.Set(v.Field(field).Addr().Convert(pointer.Type()))
+	}
+
+	return json.Unmarshal(data, pointers.Addr().Interface())
+}
+
+// decodeString calls parse with the content of the JSON string data unless it's empty.
+// Values aren't unescaped because the numeric and boolean values sent by aria2 don't contain escapes.
+func decodeString(data []byte, parse func(raw string) error) error {
+	if len(data) < 2 || data[0] != '"' || data[len(data)-1] != '"' {
+		return fmt.Errorf("invalid string value %s", data)
+	}
+
+	if raw := string(data[1 : len(data)-1]); raw != "" {
+		return parse(raw)
+	}
+
+	return nil
+}
+
+// stringBool is a bool sent as a string which may be empty.
+type stringBool bool
+
+func (b *stringBool) UnmarshalJSON(data []byte) error {
+	return decodeString(data, func(raw string) error {
+		value, err := strconv.ParseBool(raw)
+		if err == nil {
+			*b = stringBool(value)
+		}
+		return err
+	})
+}
+
+// stringInt is an int sent as a string which may be empty.
+type stringInt int
+
+func (n *stringInt) UnmarshalJSON(data []byte) error {
+	return decodeString(data, func(raw string) error {
+		value, err := strconv.Atoi(raw)
+		if err == nil {
+			*n = stringInt(value)
+		}
+		return err
+	})
+}
+
+// stringUint is a uint sent as a string which may be empty.
+type stringUint uint
+
+func (n *stringUint) UnmarshalJSON(data []byte) error {
+	return decodeString(data, func(raw string) error {
+		value, err := strconv.ParseUint(raw, 10, 0)
+		if err == nil {
+			*n = stringUint(value)
+		}
+		return err
+	})
+}
+
+// stringUint8 is a uint8 sent as a string which may be empty.
+type stringUint8 uint8
+
+func (n *stringUint8) UnmarshalJSON(data []byte) error {
+	return decodeString(data, func(raw string) error {
+		value, err := strconv.ParseUint(raw, 10, 8)
+		if err == nil {
+			*n = stringUint8(value)
+		}
+		return err
+	})
+}
+
+// IsBitTorrent reports whether the download is a BitTorrent download.
+// This requires the InfoHash to be included in the status.
+func (s Status) IsBitTorrent() bool {
+	return s.InfoHash != ""
+}
+
+// IsSeeding reports whether the download is an active BitTorrent download which only uploads.
+// This is the case if aria2 reports the local endpoint as a seeder
+// or if the download is still active although all of it is completed.
+func (s Status) IsSeeding() bool {
+	if s.Status != StatusActive {
+		return false
+	}
+
+	return s.Seeder || (s.TotalLength > 0 && s.CompletedLength >= s.TotalLength)
+}
+
+// Ratio returns the share ratio of the download, which is the uploaded length
+// divided by the completed length.
+// If nothing is completed yet, 0 is returned.
+func (s Status) Ratio() float64 {
+	if s.CompletedLength == 0 {
+		return 0
+	}
+
+	return float64(s.UploadLength) / float64(s.CompletedLength)
+}
+
+// TorrentSummary returns the information about the torrent of the download.
+// The fields of the summary are only set if the corresponding keys are included in the status.
+func (s Status) TorrentSummary() TorrentSummary {
+	return TorrentSummary{
+		Name:        s.BitTorrent.Info.Name,
+		Mode:        s.BitTorrent.Mode,
+		InfoHash:    s.InfoHash,
+		TotalLength: s.TotalLength,
+		PieceLength: s.PieceLength,
+		NumPieces:   s.NumPieces,
+	}
+}
+
+// Pieces decodes the BitField into a slice with an element for each piece.
+// An element is true if the corresponding piece is loaded.
+// The slice has a length of NumPieces, the overflow bits at the end of the BitField are discarded.
+//
+// If the download wasn't started yet (BitField is empty) an empty slice is returned.
+func (s Status) Pieces() ([]bool, error) {
+	return decodeBitField(s.BitField, s.NumPieces)
+}
+
+// decodeBitField decodes the hexadecimal bitfield used by aria2
+// into a slice of numPieces elements.
+func decodeBitField(bitField string, numPieces uint) ([]bool, error) {
+	if bitField == "" {
+		return []bool{}, nil
+	}
+
+	data, err := hex.DecodeString(bitField)
+	if err != nil {
+		return nil, err
+	}
+
+	if uint(len(data))*8 < numPieces {
+		return nil, fmt.Errorf("bitfield contains %d bits but there are %d pieces", len(data)*8, numPieces)
+	}
+
+	pieces := make([]bool, numPieces)
+	for i := range pieces {
+		pieces[i] = data[i/8]&(0x80>>uint(i%8)) != 0
+	}
+
+	return pieces, nil
+}
+
+// PiecesCompleted returns the number of loaded pieces according to the BitField.
+func (s Status) PiecesCompleted() (uint, error) {
+	pieces, err := s.Pieces()
+	if err != nil {
+		return 0, err
+	}
+
+	var completed uint
+	for _, loaded := range pieces {
+		if loaded {
+			completed++
+		}
+	}
+
+	return completed, nil
+}
+
+// FilePaths returns the paths of the files of the download.
+// Relative file paths are resolved against Dir, absolute ones are kept as they are.
+// The paths follow the conventions of the system aria2 runs on, which isn't necessarily the local one.
+// Windows paths are returned with backslashes only, even if aria2 reported them with mixed separators.
+// Files whose path isn't known yet, for example before the metadata of a magnet link is downloaded,
+// are skipped.
+func (s Status) FilePaths() []string {
+	paths := make([]string, 0, len(s.Files))
+	for _, file := range s.Files {
+		if file.Path == "" {
+			continue
+		}
+
+		paths = append(paths, joinPath(s.Dir, file.Path))
+	}
+
+	return paths
+}
+
+// joinPath joins the directory dir and the path p as reported by aria2.
+// Package path/filepath can't be used because it only knows the conventions of the local system.
+func joinPath(dir, p string) string {
+	joined := p
+	if dir != "" && !isAbsPath(p) {
+		joined = strings.TrimRight(dir, `/\`) + "/" + strings.TrimPrefix(p, "./")
+	}
+
+	if isWindowsPath(dir) || isWindowsPath(p) {
+		joined = strings.Replace(joined, "/", `\`, -1)
+	}
+
+	return joined
+}
+
+// isAbsPath reports whether p is an absolute unix or Windows path.
+func isAbsPath(p string) bool {
+	return strings.HasPrefix(p, "/") || strings.HasPrefix(p, `\`) || hasDriveLetter(p)
+}
+
+// isWindowsPath reports whether p looks like a Windows path.
+func isWindowsPath(p string) bool {
+	return hasDriveLetter(p) || strings.Contains(p, `\`)
+}
+
+// hasDriveLetter reports whether p starts with a Windows drive letter like "C:".
+func hasDriveLetter(p string) bool {
+	if len(p) < 2 || p[1] != ':' {
+		return false
+	}
+
+	letter := p[0] | 0x20
+	return letter >= 'a' && letter <= 'z'
+}
+
+// SumDownloadSpeed returns the combined download speed of the given downloads in bytes/sec.
+func SumDownloadSpeed(statuses []Status) uint {
+	var speed uint
+	for _, status := range statuses {
+		speed += status.DownloadSpeed
+	}
+
+	return speed
+}
+
+// SumUploadSpeed returns the combined upload speed of the given downloads in bytes/sec.
+func SumUploadSpeed(statuses []Status) uint {
+	var speed uint
+	for _, status := range statuses {
+		speed += status.UploadSpeed
+	}
+
+	return speed
+}
+
+// UnknownETA is returned by Status.ETA() when the remaining time can't be estimated.
+const UnknownETA time.Duration = -1
+
+// Progress returns the fraction of the download which is completed, ranging from 0 to 1.
+// If the total length isn't known yet, 0 is returned.
+func (s Status) Progress() float64 {
+	if s.TotalLength == 0 {
+		return 0
+	}
+
+	return float64(s.CompletedLength) / float64(s.TotalLength)
+}
+
+// ETA estimates the time remaining until the download is completed
+// based on the current download speed.
+// UnknownETA is returned if the download speed or the total length is 0.
+func (s Status) ETA() time.Duration {
+	if s.DownloadSpeed == 0 || s.TotalLength == 0 {
+		return UnknownETA
+	}
+
+	if s.CompletedLength >= s.TotalLength {
+		return 0
+	}
+
+	remaining := float64(s.TotalLength - s.CompletedLength)
+	return time.Duration(remaining / float64(s.DownloadSpeed) * float64(time.Second))
+}
+
+// IsVerifying reports whether the download is currently being hash checked.
+// aria2 only reports the VerifiedLength during a hash check, so a check which
+// hasn't verified any bytes yet can't be detected.
+// Use VerifyIntegrityPending to check whether the download is waiting for a hash check.
+func (s Status) IsVerifying() bool {
+	return s.VerifiedLength > 0 && !s.VerifyIntegrityPending
+}
+
+// VerifyProgress returns the fraction of the download which was verified by the
+// current hash check, ranging from 0 to 1.
+// If the total length isn't known, 0 is returned.
+func (s Status) VerifyProgress() float64 {
+	if s.TotalLength == 0 {
+		return 0
+	}
+
+	return float64(s.VerifiedLength) / float64(s.TotalLength)
+}
+
+// Validate performs sanity checks on the status and returns an error describing
+// the first inconsistency it finds.
+// This can be used to detect corrupted responses or changes of the protocol early.
+//
+// The following is checked:
+//   - CompletedLength doesn't exceed TotalLength
+//   - NumPieces pieces of PieceLength bytes cover TotalLength
+//   - BitField is a hexadecimal string with a bit for each piece and the overflow bits set to zero
+//   - the lengths of the selected Files add up to TotalLength
+//     and the completed length of a file doesn't exceed its length
+//
+// If only some files of a torrent are selected (see Options.SelectFile),
+// aria2 reports the TotalLength of the selected files while NumPieces covers the whole torrent.
+// The pieces may therefore exceed TotalLength by more than a piece.
+//
+// Checks which require a length are skipped while it isn't known yet.
+func (s Status) Validate() error {
+	if s.TotalLength > 0 && s.CompletedLength > s.TotalLength {
+		return fmt.Errorf("completed length %d exceeds total length %d", s.CompletedLength, s.TotalLength)
+	}
+
+	if s.PieceLength > 0 && s.TotalLength > 0 && s.NumPieces*s.PieceLength < s.TotalLength {
+		return fmt.Errorf("%d pieces of %d bytes don't cover total length %d",
+			s.NumPieces, s.PieceLength, s.TotalLength)
+	}
+
+	if s.BitField != "" {
+		if err := validateBitField(s.BitField, s.NumPieces); err != nil {
+			return err
+		}
+	}
+
+	var length uint
+	selected := false
+	for _, file := range s.Files {
+		if file.CompletedLength > file.Length {
+			return fmt.Errorf("completed length %d of file %d exceeds its length %d",
+				file.CompletedLength, file.Index, file.Length)
+		}
+
+		if file.Selected {
+			length += file.Length
+			selected = true
+		}
+	}
+
+	// the selected key may not have been requested, in which case no file appears to be selected
+	if selected && length != s.TotalLength {
+		return fmt.Errorf("lengths of the selected files add up to %d but total length is %d", length, s.TotalLength)
+	}
+
+	return nil
+}
+
+// validateBitField checks that bitField contains exactly the bytes needed for numPieces bits
+// and that the overflow bits are zero.
+func validateBitField(bitField string, numPieces uint) error {
+	data, err := hex.DecodeString(bitField)
+	if err != nil {
+		return fmt.Errorf("invalid bitfield: %v", err)
+	}
+
+	if expected := (numPieces + 7) / 8; uint(len(data)) != expected {
+		return fmt.Errorf("bitfield contains %d bytes but %d pieces require %d", len(data), numPieces, expected)
+	}
+
+	if overflow := numPieces % 8; overflow > 0 && data[len(data)-1]&(0xff>>overflow) != 0 {
+		return errors.New("overflow bits of bitfield are set")
+	}
+
+	return nil
+}
+
 // UNIXTime is a wrapper around time.Time that marshals to a unix timestamp.
 type UNIXTime struct {
 	time.Time
@@ -91,12 +518,19 @@ func (t UNIXTime) MarshalJSON() ([]byte, error) {
 	return json.Marshal(t.Unix())
 }
 
-// UnmarshalJSON loads a unix timestamp
+// UnmarshalJSON loads a unix timestamp.
+// The timestamp may be given as a number or as a string containing the number.
+// An empty string results in the zero time.
 func (t *UNIXTime) UnmarshalJSON(data []byte) error {
-	var ts int64
-	err := json.Unmarshal(data, &ts)
+	raw := strings.Trim(string(data), `"`)
+	if raw == "" {
+		*t = UNIXTime{}
+		return nil
+	}
+
+	ts, err := strconv.ParseInt(raw, 10, 64)
 	if err != nil {
-		return err
+		return fmt.Errorf("invalid unix timestamp %s: %v", data, err)
 	}
 
 	*t = UNIXTime{time.Unix(ts, 0)}
@@ -115,14 +549,53 @@ const (
 
 // BitTorrentStatus holds information for a BitTorrent download
 type BitTorrentStatus struct {
-	// List of lists of announce URIs.
+	// List of tiers of announce URIs, each tier being a list of tracker URIs.
 	// If the torrent contains announce and no announce-list,
 	// announce is converted to the announce-list format
-	AnnounceList []URI                `json:"announceList"`
-	Comment      string               `json:"comment"`             // The comment of the torrent
-	CreationDate UNIXTime             `json:"creationDate,string"` // The creation time of the torrent
-	Mode         TorrentMode          `json:"mode"`                // File mode of the torrent
-	Info         BitTorrentStatusInfo `json:"info"`                // Information from the info dictionary
+	AnnounceList [][]string           `json:"announceList"`
+	Comment      string               `json:"comment"`      // The comment of the torrent
+	CreationDate UNIXTime             `json:"creationDate"` // The creation time of the torrent
+	Mode         TorrentMode          `json:"mode"`         // File mode of the torrent
+	Info         BitTorrentStatusInfo `json:"info"`         // Information from the info dictionary
+}
+
+// Tiers returns the tiers of AnnounceList with each tracker as a URI,
+// preserving the grouping aria2 reported.
+// The URIs don't have a Status because aria2 doesn't report one for trackers.
+func (b BitTorrentStatus) Tiers() [][]URI {
+	if b.AnnounceList == nil {
+		return nil
+	}
+
+	tiers := make([][]URI, len(b.AnnounceList))
+	for i, trackers := range b.AnnounceList {
+		tiers[i] = make([]URI, len(trackers))
+		for j, tracker := range trackers {
+			tiers[i][j] = URI{URI: tracker}
+		}
+	}
+
+	return tiers
+}
+
+// InfoHashSummary holds the BitTorrent specific fields of a Status
+// which aren't part of BitTorrentStatus.
+type InfoHashSummary struct {
+	InfoHash    string // InfoHash of the torrent
+	NumSeeders  uint   // The number of seeders aria2 has connected to
+	Seeder      bool   // true if the local endpoint is a seeder
+	Connections uint   // The number of peers aria2 has connected to
+}
+
+// TorrentSummary aggregates the information about the torrent of a BitTorrent download.
+// It's returned by the Status.TorrentSummary() method.
+type TorrentSummary struct {
+	Name        string      // Name in the info dictionary
+	Mode        TorrentMode // File mode of the torrent
+	InfoHash    string      // InfoHash of the torrent
+	TotalLength uint        // Total length of the torrent in bytes
+	PieceLength uint        // Piece length in bytes
+	NumPieces   uint        // The number of pieces
 }
 
 // A BitTorrentStatusInfo holds information from the info dictionary.
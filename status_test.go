@@ -3,7 +3,9 @@ package arigo
 import (
 	"encoding/json"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestStatusFormat(t *testing.T) {
@@ -57,3 +59,257 @@ func TestStatusFormat(t *testing.T) {
 	assert.EqualValues(t, true, file1.Selected)
 	assert.Equal(t, []URI{{Status: URIUsed, URI: "http://example.org/file"}}, file1.URIs)
 }
+
+func TestStatusPieces(t *testing.T) {
+	status := Status{BitField: "a1c0", NumPieces: 10}
+
+	pieces, err := status.Pieces()
+	assert.NoError(t, err)
+	assert.Equal(t, []bool{
+		true, false, true, false, false, false, false, true,
+		true, true,
+	}, pieces)
+
+	completed, err := status.PiecesCompleted()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, completed)
+
+	pieces, err = Status{NumPieces: 10}.Pieces()
+	assert.NoError(t, err)
+	assert.Empty(t, pieces)
+
+	_, err = Status{BitField: "ff", NumPieces: 10}.Pieces()
+	assert.Error(t, err)
+
+	_, err = Status{BitField: "zz", NumPieces: 2}.Pieces()
+	assert.Error(t, err)
+}
+
+func TestStatusProgress(t *testing.T) {
+	status := Status{TotalLength: 1000, CompletedLength: 250, DownloadSpeed: 50}
+	assert.Equal(t, 0.25, status.Progress())
+	assert.Equal(t, 15*time.Second, status.ETA())
+
+	assert.Equal(t, float64(0), Status{}.Progress())
+	assert.Equal(t, UnknownETA, Status{TotalLength: 1000}.ETA())
+	assert.Equal(t, time.Duration(0), Status{TotalLength: 1000, CompletedLength: 1000, DownloadSpeed: 50}.ETA())
+}
+
+func TestStatusVerify(t *testing.T) {
+	status := Status{TotalLength: 1000, CompletedLength: 1000, VerifiedLength: 400}
+	assert.True(t, status.IsVerifying())
+	assert.Equal(t, 0.4, status.VerifyProgress())
+
+	assert.False(t, Status{TotalLength: 1000}.IsVerifying())
+	assert.False(t, Status{TotalLength: 1000, VerifyIntegrityPending: true}.IsVerifying())
+	assert.Equal(t, float64(0), Status{VerifiedLength: 400}.VerifyProgress())
+}
+
+func TestUNIXTimeFormat(t *testing.T) {
+	for _, data := range []string{`1583368597`, `"1583368597"`} {
+		var ts UNIXTime
+		require.NoError(t, json.Unmarshal([]byte(data), &ts), data)
+		assert.Equal(t, int64(1583368597), ts.Unix(), data)
+	}
+
+	var status BitTorrentStatus
+	require.NoError(t, json.Unmarshal([]byte(`{"creationDate": 1583368597}`), &status))
+	assert.Equal(t, int64(1583368597), status.CreationDate.Unix())
+
+	var ts UNIXTime
+	require.NoError(t, json.Unmarshal([]byte(`""`), &ts))
+	assert.True(t, ts.IsZero())
+
+	assert.Error(t, json.Unmarshal([]byte(`"yesterday"`), &ts))
+}
+
+func TestStatusEmptyNumbers(t *testing.T) {
+	data := []byte(`{
+		"gid": "2089b05ecca3d829",
+		"status": "waiting",
+		"totalLength": "",
+		"numPieces": "",
+		"seeder": "",
+		"errorCode": "",
+		"files": [{"index": "1", "length": "", "completedLength": "", "selected": "true"}]
+	}`)
+
+	var status Status
+	require.NoError(t, json.Unmarshal(data, &status))
+
+	assert.Equal(t, "2089b05ecca3d829", status.GID)
+	assert.EqualValues(t, 0, status.TotalLength)
+	assert.EqualValues(t, 0, status.NumPieces)
+	assert.False(t, status.Seeder)
+	assert.Equal(t, Success, status.ErrorCode)
+	assert.Equal(t, []File{{Index: 1, Selected: true}}, status.Files)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"totalLength": "abc"}`), &status))
+	assert.Error(t, json.Unmarshal([]byte(`{"totalLength": 5}`), &status))
+}
+
+func TestLenientDecoderStringFields(t *testing.T) {
+	// every numeric and boolean field tagged with the string option must accept empty strings
+	for _, decoder := range []lenientDecoder{statusDecoder, fileDecoder} {
+		for i := 0; i < decoder.pointers.NumField(); i++ {
+			field := decoder.pointers.Field(i)
+			assert.NotContains(t, field.Tag.Get("json"), ",string", field.Name)
+		}
+	}
+}
+
+func TestBitTorrentStatusFormat(t *testing.T) {
+	data := []byte(`{
+		"announceList": [
+			["udp://tracker.example.org:1337/announce"],
+			["http://tracker1.example.org/announce", "http://tracker2.example.org/announce"]
+		],
+		"comment": "test torrent",
+		"creationDate": 1583368597,
+		"mode": "multi",
+		"info": {"name": "test"}
+	}`)
+
+	var status BitTorrentStatus
+	require.NoError(t, json.Unmarshal(data, &status))
+
+	assert.Equal(t, [][]string{
+		{"udp://tracker.example.org:1337/announce"},
+		{"http://tracker1.example.org/announce", "http://tracker2.example.org/announce"},
+	}, status.AnnounceList)
+	assert.Equal(t, [][]URI{
+		{{URI: "udp://tracker.example.org:1337/announce"}},
+		{{URI: "http://tracker1.example.org/announce"}, {URI: "http://tracker2.example.org/announce"}},
+	}, status.Tiers())
+	assert.Nil(t, BitTorrentStatus{}.Tiers())
+	assert.Equal(t, "test torrent", status.Comment)
+	assert.Equal(t, TorrentModeMulti, status.Mode)
+	assert.Equal(t, "test", status.Info.Name)
+}
+
+func TestSumSpeed(t *testing.T) {
+	statuses := []Status{
+		{DownloadSpeed: 100, UploadSpeed: 10},
+		{DownloadSpeed: 250, UploadSpeed: 0},
+		{DownloadSpeed: 0, UploadSpeed: 5},
+	}
+
+	assert.EqualValues(t, 350, SumDownloadSpeed(statuses))
+	assert.EqualValues(t, 15, SumUploadSpeed(statuses))
+	assert.EqualValues(t, 0, SumDownloadSpeed(nil))
+}
+
+func TestStatusFilePaths(t *testing.T) {
+	status := Status{
+		Dir: "/downloads/",
+		Files: []File{
+			{Path: "a.mkv"},
+			{Path: "./sub/b.mkv"},
+			{Path: "/elsewhere/c.mkv"},
+			{Path: ""},
+		},
+	}
+	assert.Equal(t, []string{"/downloads/a.mkv", "/downloads/sub/b.mkv", "/elsewhere/c.mkv"}, status.FilePaths())
+
+	status = Status{
+		Dir: `C:\Downloads`,
+		Files: []File{
+			{Path: "C:/Downloads/a.mkv"},
+			{Path: `sub/b.mkv`},
+			{Path: `D:\c.mkv`},
+		},
+	}
+	assert.Equal(t, []string{`C:\Downloads\a.mkv`, `C:\Downloads\sub\b.mkv`, `D:\c.mkv`}, status.FilePaths())
+
+	assert.Empty(t, Status{Dir: "/downloads"}.FilePaths())
+}
+
+func TestStatusIsBitTorrent(t *testing.T) {
+	assert.True(t, Status{InfoHash: "248d0a1cd08284299de78d5c1ed359bb46717d8c"}.IsBitTorrent())
+	assert.False(t, Status{}.IsBitTorrent())
+}
+
+func TestStatusTorrentSummary(t *testing.T) {
+	status := Status{
+		InfoHash:    "248d0a1cd08284299de78d5c1ed359bb46717d8c",
+		TotalLength: 3 * 1024,
+		PieceLength: 1024,
+		NumPieces:   3,
+		BitTorrent:  BitTorrentStatus{Mode: TorrentModeMulti, Info: BitTorrentStatusInfo{Name: "season"}},
+	}
+
+	assert.Equal(t, TorrentSummary{
+		Name:        "season",
+		Mode:        TorrentModeMulti,
+		InfoHash:    "248d0a1cd08284299de78d5c1ed359bb46717d8c",
+		TotalLength: 3 * 1024,
+		PieceLength: 1024,
+		NumPieces:   3,
+	}, status.TorrentSummary())
+}
+
+func TestStatusIsSeeding(t *testing.T) {
+	assert.True(t, Status{Status: StatusActive, Seeder: true}.IsSeeding())
+	assert.True(t, Status{Status: StatusActive, TotalLength: 10, CompletedLength: 10}.IsSeeding())
+	assert.False(t, Status{Status: StatusActive, TotalLength: 10, CompletedLength: 5}.IsSeeding())
+	assert.False(t, Status{Status: StatusActive}.IsSeeding())
+	assert.False(t, Status{Status: StatusCompleted, TotalLength: 10, CompletedLength: 10}.IsSeeding())
+}
+
+func TestStatusRatio(t *testing.T) {
+	assert.Equal(t, 1.5, Status{CompletedLength: 100, UploadLength: 150}.Ratio())
+	assert.Equal(t, float64(0), Status{UploadLength: 150}.Ratio())
+}
+
+func TestStatusValidate(t *testing.T) {
+	valid := Status{
+		TotalLength:     2500,
+		CompletedLength: 1000,
+		PieceLength:     1000,
+		NumPieces:       3,
+		BitField:        "80",
+		Files: []File{
+			{Index: 1, Length: 2000, CompletedLength: 1000, Selected: true},
+			{Index: 2, Length: 500, Selected: true},
+		},
+	}
+	assert.NoError(t, valid.Validate())
+	assert.NoError(t, Status{}.Validate(), "status of a download which wasn't started should be valid")
+
+	invalid := map[string]func(s *Status){
+		"completed length":  func(s *Status) { s.CompletedLength = 3000 },
+		"too few pieces":    func(s *Status) { s.NumPieces = 2 },
+		"bitfield hex":      func(s *Status) { s.BitField = "zz" },
+		"bitfield length":   func(s *Status) { s.BitField = "8000" },
+		"bitfield overflow": func(s *Status) { s.BitField = "81" },
+		"file lengths":      func(s *Status) { s.Files = s.Files[:1] },
+		"file completed":    func(s *Status) { s.Files = []File{{Index: 1, Length: 2500, CompletedLength: 2600, Selected: true}} },
+	}
+
+	for name, modify := range invalid {
+		status := valid
+		modify(&status)
+		assert.Error(t, status.Validate(), name)
+	}
+}
+
+func TestStatusValidateSelectedFiles(t *testing.T) {
+	// only the second and third file of the torrent are selected,
+	// the pieces still cover all of the files
+	status := Status{
+		TotalLength:     1500,
+		CompletedLength: 500,
+		PieceLength:     1000,
+		NumPieces:       4,
+		BitField:        "40",
+		Files: []File{
+			{Index: 1, Length: 2000},
+			{Index: 2, Length: 1000, CompletedLength: 500, Selected: true},
+			{Index: 3, Length: 500, Selected: true},
+		},
+	}
+	assert.NoError(t, status.Validate())
+
+	status.Files[0].Selected = true
+	assert.Error(t, status.Validate())
+}
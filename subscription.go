@@ -0,0 +1,241 @@
+package arigo
+
+import (
+	"context"
+	"sync"
+)
+
+// OverflowPolicy determines what happens to an event which is delivered
+// to a Subscription while the channel of the event is full.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock waits until there's room in the channel.
+	// Other listeners for the same event are held up in the meantime.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest discards the oldest event in the channel to make room for the new one.
+	OverflowDropOldest
+	// OverflowDropNewest discards the new event.
+	OverflowDropNewest
+)
+
+// SubscriptionOptions configures a Subscription.
+type SubscriptionOptions struct {
+	// BufferSize is the capacity of each channel of the subscription.
+	// OverflowDropOldest requires a capacity of at least 1, smaller values are treated as 1.
+	BufferSize int
+
+	// Overflow is the policy for events which are delivered while their channel is full.
+	// Dropped events are counted using Metrics.IncDroppedEvents().
+	// It doesn't apply to the Resumed channel, which always keeps only the latest ResumeEvent.
+	Overflow OverflowPolicy
+
+	// ErrorStatus makes the subscription retrieve the status of every download
+	// before delivering its ErrorEvent. The status is set as the Status of the event.
+	// This costs an additional call for every error.
+	ErrorStatus bool
+}
+
+// Subscription delivers download events over channels.
+// There is one channel for every EventType.
+//
+// Events are delivered in the background. By default, as long as an event hasn't been received
+// from its channel, other listeners for the same event are held up.
+// Use a buffer size that fits your consumer, make sure to always receive from all channels
+// or choose an OverflowPolicy which drops events.
+//
+// Subscriptions keep delivering events after the client re-established a lost connection.
+// Because events may have been missed in the meantime, a ResumeEvent
+// is delivered on Resumed whenever that happens.
+// Resumed has a capacity of 1; if the previous ResumeEvent wasn't received yet,
+// it's replaced by the new one.
+type Subscription struct {
+	Started     <-chan *DownloadEvent // Receives StartEvent
+	Paused      <-chan *DownloadEvent // Receives PauseEvent
+	Stopped     <-chan *DownloadEvent // Receives StopEvent
+	Completed   <-chan *DownloadEvent // Receives CompleteEvent
+	BTCompleted <-chan *DownloadEvent // Receives BTCompleteEvent
+	Error       <-chan *DownloadEvent // Receives ErrorEvent
+	Resumed     <-chan *ResumeEvent   // Receives a ResumeEvent after the connection was re-established
+
+	channels    []chan *DownloadEvent
+	resumed     chan *ResumeEvent
+	unsubscribe []UnsubscribeFunc
+	done        chan struct{}
+	cancel      context.CancelFunc
+	closeOnce   sync.Once
+
+	// mut guards closing the channels against running deliveries.
+	// Listeners may still be called after unsubscribing, so they hold a read lock
+	// and don't deliver once closed is set.
+	mut    sync.RWMutex
+	closed bool
+}
+
+// newSubscription subscribes to all events of target and to the reconnects of client.
+// If gid isn't empty, ResumeEvents only contain the status of the download with that gid.
+// The subscription is closed automatically once client is closed or parent is done.
+func newSubscription(parent context.Context, target EventSubscriber, client *Client, gid string, options SubscriptionOptions) *Subscription {
+	bufferSize := options.BufferSize
+	if options.Overflow == OverflowDropOldest && bufferSize < 1 {
+		bufferSize = 1
+	}
+
+	// ctx is used for the calls made by the subscription, it's canceled when the subscription is closed.
+	ctx, cancel := context.WithCancel(parent)
+
+	sub := &Subscription{
+		resumed: make(chan *ResumeEvent, 1),
+		done:    make(chan struct{}),
+		cancel:  cancel,
+	}
+
+	subscribe := func(evtType EventType) <-chan *DownloadEvent {
+		ch := make(chan *DownloadEvent, bufferSize)
+		sub.channels = append(sub.channels, ch)
+
+		withStatus := evtType == ErrorEvent && options.ErrorStatus
+
+		sub.unsubscribe = append(sub.unsubscribe, target.Subscribe(evtType, func(event *DownloadEvent) {
+			sub.mut.RLock()
+			defer sub.mut.RUnlock()
+
+			if sub.closed {
+				return
+			}
+
+			if withStatus {
+				event = eventWithStatus(ctx, client, event)
+			}
+
+			sub.deliver(ch, event, options.Overflow, func() {
+				client.metrics.IncDroppedEvents(evtType)
+			})
+		}))
+
+		return ch
+	}
+
+	sub.Started = subscribe(StartEvent)
+	sub.Paused = subscribe(PauseEvent)
+	sub.Stopped = subscribe(StopEvent)
+	sub.Completed = subscribe(CompleteEvent)
+	sub.BTCompleted = subscribe(BTCompleteEvent)
+	sub.Error = subscribe(ErrorEvent)
+
+	sub.Resumed = sub.resumed
+	unsubscribeResume := client.resumeTarget.subscribe(func(event *ResumeEvent) {
+		sub.mut.RLock()
+		defer sub.mut.RUnlock()
+
+		if sub.closed {
+			return
+		}
+
+		if gid != "" {
+			event = event.filter(gid)
+		}
+
+		for {
+			select {
+			case sub.resumed <- event:
+				return
+			default:
+			}
+
+			select {
+			case <-sub.resumed:
+			default:
+			}
+		}
+	})
+	sub.unsubscribe = append(sub.unsubscribe, func() bool {
+		unsubscribeResume()
+		return true
+	})
+
+	go func() {
+		select {
+		case <-client.closing:
+			sub.Close()
+		case <-parent.Done():
+			sub.Close()
+		case <-sub.done:
+		}
+	}()
+
+	return sub
+}
+
+// eventWithStatus returns a copy of event with the current status of the download.
+// If the status can't be retrieved, for example because the download result was already removed,
+// the Status of the copy is nil.
+func eventWithStatus(ctx context.Context, client *Client, event *DownloadEvent) *DownloadEvent {
+	enriched := *event
+
+	if status, err := client.TellStatusWithContext(ctx, event.GID); err == nil {
+		enriched.Status = &status
+	}
+
+	return &enriched
+}
+
+// deliver sends event to ch according to the overflow policy.
+// dropped is called for every event which is discarded.
+func (s *Subscription) deliver(ch chan *DownloadEvent, event *DownloadEvent, policy OverflowPolicy, dropped func()) {
+	switch policy {
+	case OverflowDropNewest:
+		select {
+		case ch <- event:
+		default:
+			dropped()
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case ch <- event:
+				return
+			default:
+			}
+
+			select {
+			case <-ch:
+				dropped()
+			default:
+			}
+		}
+	default:
+		select {
+		case ch <- event:
+		case <-s.done:
+		}
+	}
+}
+
+// Close unregisters the subscription and closes all of its channels.
+// Subscriptions are also closed when their client is closed.
+// Events which haven't been received yet are discarded.
+// Calling Close more than once has no effect.
+func (s *Subscription) Close() {
+	s.closeOnce.Do(func() {
+		// unblock pending deliveries, they have to return
+		// before the channels can be closed.
+		close(s.done)
+		s.cancel()
+
+		for _, unsubscribe := range s.unsubscribe {
+			unsubscribe()
+		}
+
+		s.mut.Lock()
+		defer s.mut.Unlock()
+
+		s.closed = true
+
+		for _, ch := range s.channels {
+			close(ch)
+		}
+
+		close(s.resumed)
+	})
+}
@@ -0,0 +1,175 @@
+package arigo
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/jae-jae/arigo/pkg/aria2proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestSubscription(t *testing.T) {
+	var evtTarget eventTarget
+
+	client := newClient("", nil)
+	sub := newSubscription(context.Background(), &evtTarget, client, "", SubscriptionOptions{BufferSize: 1})
+
+	evtTarget.Dispatch(CompleteEvent, &DownloadEvent{GID: "1"})
+	evtTarget.Dispatch(ErrorEvent, &DownloadEvent{GID: "2"})
+
+	assert.Equal(t, "1", (<-sub.Completed).GID)
+	assert.Equal(t, "2", (<-sub.Error).GID)
+
+	// the buffer is full, the second dispatch blocks until the subscription is closed
+	evtTarget.Dispatch(StartEvent, &DownloadEvent{GID: "3"})
+	dispatched := make(chan struct{})
+	go func() {
+		evtTarget.Dispatch(StartEvent, &DownloadEvent{GID: "4"})
+		close(dispatched)
+	}()
+
+	sub.Close()
+	sub.Close()
+
+	select {
+	case <-dispatched:
+	case <-time.After(time.Second):
+		t.Fatal("dispatch still blocked after closing the subscription")
+	}
+
+	assert.Empty(t, evtTarget.listenerMap)
+	assert.Empty(t, client.resumeTarget.listeners)
+
+	_, ok := <-sub.Paused
+	assert.False(t, ok, "channels should be closed")
+}
+
+func TestSubscriptionWithContext(t *testing.T) {
+	client := newClient("", nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	sub, err := client.NewSubscriptionWithContext(ctx, SubscriptionOptions{BufferSize: 1})
+	require.NoError(t, err)
+
+	cancel()
+
+	select {
+	case _, ok := <-sub.Started:
+		assert.False(t, ok, "channel should be closed")
+	case <-time.After(time.Second):
+		t.Fatal("subscription wasn't closed after canceling the context")
+	}
+
+	assert.Empty(t, client.evtTarget.listenerMap)
+
+	_, err = client.NewSubscriptionWithContext(ctx, SubscriptionOptions{})
+	assert.Equal(t, context.Canceled, err)
+
+	client.markClosed()
+	_, err = client.NewSubscriptionWithContext(context.Background(), SubscriptionOptions{})
+	assert.Equal(t, ErrClientClosed, err)
+}
+
+func TestGIDSubscription(t *testing.T) {
+	var client Client
+	first, second := client.GetGID("1"), client.GetGID("2")
+
+	firstSub := first.NewSubscription(1)
+	secondSub := second.NewSubscription(1)
+	defer secondSub.Close()
+
+	client.evtTarget.Dispatch(StartEvent, &DownloadEvent{GID: "2"})
+	client.evtTarget.Dispatch(StartEvent, &DownloadEvent{GID: "1"})
+
+	assert.Equal(t, "1", (<-firstSub.Started).GID)
+	assert.Equal(t, "2", (<-secondSub.Started).GID)
+
+	firstSub.Close()
+
+	client.evtTarget.Dispatch(CompleteEvent, &DownloadEvent{GID: "2"})
+	assert.Equal(t, "2", (<-secondSub.Completed).GID)
+}
+
+func TestSubscriptionBTComplete(t *testing.T) {
+	client := newClient("", nil)
+	sub := client.NewSubscription(1)
+	defer sub.Close()
+
+	client.handleNotification(aria2proto.OnBTDownloadComplete, json.RawMessage(`[{"gid": "2089b05ecca3d829"}]`))
+
+	assert.Equal(t, "2089b05ecca3d829", (<-sub.BTCompleted).GID)
+	assert.Empty(t, sub.Completed, "BitTorrent completion must not be delivered as a regular completion")
+}
+
+func TestSubscriptionResumed(t *testing.T) {
+	client := newClient("", nil)
+
+	sub := client.NewSubscription(1)
+	defer sub.Close()
+
+	gidSub := (&GID{GID: "2", client: client}).NewSubscription(1)
+	defer gidSub.Close()
+
+	client.resumeTarget.dispatch(&ResumeEvent{
+		Active:  []Status{{GID: "1"}, {GID: "2"}},
+		Stopped: []Status{{GID: "3"}},
+	})
+
+	assert.Equal(t, &ResumeEvent{
+		Active:  []Status{{GID: "1"}, {GID: "2"}},
+		Stopped: []Status{{GID: "3"}},
+	}, <-sub.Resumed)
+	assert.Equal(t, &ResumeEvent{Active: []Status{{GID: "2"}}, Stopped: []Status{}}, <-gidSub.Resumed)
+}
+
+func TestSubscriptionOverflow(t *testing.T) {
+	metrics := &recordingMetrics{}
+	client := newClient("", []ClientOption{WithMetrics(metrics)})
+
+	dropOldest := client.NewSubscriptionWithOptions(SubscriptionOptions{BufferSize: 2, Overflow: OverflowDropOldest})
+	defer dropOldest.Close()
+
+	dropNewest := client.NewSubscriptionWithOptions(SubscriptionOptions{BufferSize: 2, Overflow: OverflowDropNewest})
+	defer dropNewest.Close()
+
+	for _, gid := range []string{"1", "2", "3"} {
+		client.evtTarget.Dispatch(StartEvent, &DownloadEvent{GID: gid})
+	}
+
+	assert.Equal(t, "2", (<-dropOldest.Started).GID)
+	assert.Equal(t, "3", (<-dropOldest.Started).GID)
+
+	assert.Equal(t, "1", (<-dropNewest.Started).GID)
+	assert.Equal(t, "2", (<-dropNewest.Started).GID)
+
+	metrics.mut.Lock()
+	defer metrics.mut.Unlock()
+	assert.Equal(t, []EventType{StartEvent, StartEvent}, metrics.dropped)
+}
+
+func TestSubscriptionErrorStatus(t *testing.T) {
+	client := startTestClient(t, func(method string, params []interface{}) interface{} {
+		if params[0] != "2089b05ecca3d829" {
+			return &RPCError{Code: 1, Message: "GID is not found"}
+		}
+
+		return map[string]interface{}{"gid": "2089b05ecca3d829", "status": "error", "errorCode": "3", "errorMessage": "Resource not found"}
+	})
+
+	sub := client.NewSubscriptionWithOptions(SubscriptionOptions{BufferSize: 2, ErrorStatus: true})
+	defer sub.Close()
+
+	client.handleNotification(aria2proto.OnDownloadError, json.RawMessage(`[{"gid": "2089b05ecca3d829"}]`))
+	client.handleNotification(aria2proto.OnDownloadError, json.RawMessage(`[{"gid": "d2703803b52216d1"}]`))
+
+	event := <-sub.Error
+	require.NotNil(t, event.Status)
+	assert.Equal(t, ResourceNotFound, event.Status.ErrorCode)
+	assert.Equal(t, "Resource not found", event.Status.ErrorMessage)
+
+	event = <-sub.Error
+	assert.Equal(t, "d2703803b52216d1", event.GID)
+	assert.Nil(t, event.Status, "purged downloads are delivered without status")
+}
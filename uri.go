@@ -1,5 +1,10 @@
 package arigo
 
+import (
+	"net/url"
+	"strings"
+)
+
 // URIStatus represents the status of an uri.
 type URIStatus string
 
@@ -15,3 +20,21 @@ type URI struct {
 	URI    string    `json:"uri"`
 	Status URIStatus `json:"status"` // Status of the uri
 }
+
+// IsUsed reports whether aria2 is currently using the uri.
+func (u URI) IsUsed() bool {
+	return u.Status == URIUsed
+}
+
+// IsMagnet reports whether the uri is a BitTorrent Magnet URI.
+func (u URI) IsMagnet() bool {
+	return strings.HasPrefix(strings.ToLower(u.URI), "magnet:")
+}
+
+// Parse parses the uri into a url.URL.
+// Magnet URIs don't have a host, their parameters are returned by the Query() method of the url.
+//
+// This also works for the tracker URIs returned by BitTorrentStatus.Tiers().
+func (u URI) Parse() (*url.URL, error) {
+	return url.Parse(u.URI)
+}
@@ -21,3 +21,25 @@ func TestURIFormat(t *testing.T) {
 	assert.Equal(t, URIUsed, uri.Status)
 	assert.Equal(t, "http://example.org/file", uri.URI)
 }
+
+func TestURIParse(t *testing.T) {
+	parsed, err := URI{URI: "https://example.org:8080/path/file?a=b"}.Parse()
+	assert.NoError(t, err)
+	assert.Equal(t, "https", parsed.Scheme)
+	assert.Equal(t, "example.org", parsed.Hostname())
+
+	magnet := URI{URI: "magnet:?xt=urn:btih:c12fe1c06bba254a9dc9f519b335aa7c1367a88a&dn=My%20File&tr=udp://tracker.example.org:80/announce"}
+	assert.True(t, magnet.IsMagnet())
+
+	parsed, err = magnet.Parse()
+	assert.NoError(t, err)
+	assert.Equal(t, "magnet", parsed.Scheme)
+	assert.Empty(t, parsed.Host)
+	assert.Equal(t, "urn:btih:c12fe1c06bba254a9dc9f519b335aa7c1367a88a", parsed.Query().Get("xt"))
+	assert.Equal(t, "My File", parsed.Query().Get("dn"))
+	assert.Equal(t, "udp://tracker.example.org:80/announce", parsed.Query().Get("tr"))
+
+	assert.False(t, URI{URI: "http://example.org/file"}.IsMagnet())
+	assert.True(t, URI{Status: URIUsed}.IsUsed())
+	assert.False(t, URI{Status: URIWaiting}.IsUsed())
+}
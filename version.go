@@ -5,3 +5,14 @@ type VersionInfo struct {
 	Version         string   `json:"version"`         // Version number of aria2 as a string.
 	EnabledFeatures []string `json:"enabledFeatures"` // Slice of enabled features. Each feature is given as a string.
 }
+
+// HasFeature reports whether the given feature is enabled, for example "BitTorrent" or "Metalink".
+func (v VersionInfo) HasFeature(name string) bool {
+	for _, feature := range v.EnabledFeatures {
+		if feature == name {
+			return true
+		}
+	}
+
+	return false
+}
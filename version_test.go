@@ -38,3 +38,11 @@ func TestVersionFormat(t *testing.T) {
 		Version: "1.11.0",
 	}, version)
 }
+
+func TestVersionHasFeature(t *testing.T) {
+	version := VersionInfo{Version: "1.35.0", EnabledFeatures: []string{"BitTorrent", "Metalink"}}
+
+	assert.True(t, version.HasFeature("BitTorrent"))
+	assert.True(t, version.HasFeature("Metalink"))
+	assert.False(t, version.HasFeature("XML-RPC"))
+}